@@ -0,0 +1,128 @@
+package game
+
+import "fmt"
+
+/**
+ * Food is anything on the board that a snake can eat.  A game can have any
+ * number of food items at once, each with a kind which decides what eating it
+ * does to the snake:
+ *  1. normal food grows the snake by one
+ *  2. bonus food grows the snake by one, and is worth Value bonus points
+ *  3. grow food grows the snake by Value segments, one on each tick
+ *  4. shrink food shrinks the snake by Value segments, but never below its head
+ *  5. speed food doesn't grow the snake, but asks for the game speed to change by
+ *     Value steps (the game has no clock, so whatever drives the ticks acts on it)
+ *
+ * Food can expire, which takes it off the board at the end of its Expires tick.
+ */
+
+// FoodKind what eating a food does
+type FoodKind uint8
+
+const (
+	NormalFood FoodKind = iota // grows the snake by one
+	BonusFood                  // grows the snake by one, and scores Value bonus points
+	GrowFood                   // grows the snake by Value segments
+	ShrinkFood                 // shrinks the snake by Value segments
+	SpeedFood                  // changes the game speed by Value steps
+)
+
+// the food kinds, in order
+var foodKinds = []FoodKind{NormalFood, BonusFood, GrowFood, ShrinkFood, SpeedFood}
+
+// Convert to a printable string
+func (k FoodKind) String() string {
+	switch k {
+	case NormalFood:
+		return "normal"
+	case BonusFood:
+		return "bonus"
+	case GrowFood:
+		return "grow"
+	case ShrinkFood:
+		return "shrink"
+	case SpeedFood:
+		return "speed"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText the kind as its name
+func (k FoodKind) MarshalText() ([]byte, error) {
+	if k.String() == "unknown" {
+		return nil, fmt.Errorf("Unknown food kind %d", k)
+	}
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText a kind from its name
+func (k *FoodKind) UnmarshalText(b []byte) error {
+	fk, ok := parseFoodKind(string(b))
+	if !ok {
+		return fmt.Errorf("Unknown food kind %q", b)
+	}
+	*k = fk
+	return nil
+}
+
+// parse a food kind by its name
+func parseFoodKind(s string) (FoodKind, bool) {
+	for _, k := range foodKinds {
+		if k.String() == s {
+			return k, true
+		}
+	}
+	return NormalFood, false
+}
+
+// Food an item of food on the board
+type Food struct {
+	Point   Point    `json:"point"`
+	Kind    FoodKind `json:"kind"`
+	Value   int      `json:"value,omitempty"`   // how much of its effect the food has, for kinds that need one
+	Expires uint     `json:"expires,omitempty"` // the last tick that the food can be eaten on, 0 if it never expires
+}
+
+// NewFood normal food at a point, which never expires
+func NewFood(p Point) Food {
+	return Food{Point: p}
+}
+
+// Expired is the food gone by a tick
+func (f Food) Expired(tick uint) bool {
+	return f.Expires > 0 && tick >= f.Expires
+}
+
+// Growth how many segments eating the food grows a snake by, which is negative
+// for shrinking
+func (f Food) Growth() int {
+	switch f.Kind {
+	case NormalFood, BonusFood:
+		return 1
+	case GrowFood:
+		if f.Value < 1 {
+			return 1
+		}
+		return f.Value
+	case ShrinkFood:
+		if f.Value < 1 {
+			return -1
+		}
+		return -f.Value
+	default:
+		return 0
+	}
+}
+
+// Convert to a printable string
+func (f Food) String() string {
+	s := f.Kind.String() + " food at " + f.Point.String()
+	if f.Value != 0 {
+		s += fmt.Sprintf(" [value %d]", f.Value)
+	}
+	if f.Expires > 0 {
+		s += fmt.Sprintf(" [expires %d]", f.Expires)
+	}
+	return s
+}
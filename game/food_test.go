@@ -0,0 +1,164 @@
+package game_test
+
+import (
+	"encoding/json"
+	"github.com/james-nesbitt/snake/game"
+	"testing"
+)
+
+// a game with a snake of length 3 at (2,5)->(0,5) facing right, and no food
+// other than the given food
+func foodGame(t *testing.T, fs ...game.Food) game.Game {
+	s := game.NewSnake(game.Point{X: 0, Y: 5}, game.Right)
+	s.Grow()
+	s.Grow()
+	g, err := game.NewGame(game.Grid{X: 9, Y: 9}, s, game.Point{X: 9, Y: 9})
+	if err != nil {
+		t.Fatalf("Error creating game: %s", err)
+	}
+	g.RemoveFood(game.Point{X: 9, Y: 9})
+	for _, f := range fs {
+		if err := g.AddFood(f); err != nil {
+			t.Fatalf("Game rejected valid food %s: %s", f, err)
+		}
+	}
+	return g
+}
+
+// tick a game, and return the only result
+func foodTick(t *testing.T, g *game.Game) game.TickResult {
+	rs, err := g.Tick()
+	if err != nil {
+		t.Fatalf("Unexpected tick error: %s", err)
+	}
+	r, _ := rs.Get(0)
+	return r
+}
+
+// Test food kinds as JSON
+func Test_FoodJSON(t *testing.T) {
+	f := game.Food{Point: game.Point{X: 1, Y: 2}, Kind: game.ShrinkFood, Value: 2, Expires: 10}
+	b, err := json.Marshal(f)
+	if err != nil {
+		t.Fatalf("Error marshalling food: %s", err)
+	}
+	if string(b) != `{"point":{"X":1,"Y":2},"kind":"shrink","value":2,"expires":10}` {
+		t.Errorf("Food marshalled as %s", b)
+	}
+
+	rf := game.Food{}
+	if err := json.Unmarshal(b, &rf); err != nil || rf != f {
+		t.Errorf("Food did not unmarshal: %s %s", rf, err)
+	}
+	if err := json.Unmarshal([]byte(`{"kind":"poison"}`), &rf); err == nil {
+		t.Errorf("Unmarshalling an unknown food kind did not produce an error")
+	}
+}
+
+// Test that a game can have more than one food, and says which was eaten
+func Test_FoodMultiple(t *testing.T) {
+	a := game.NewFood(game.Point{X: 3, Y: 5})
+	b := game.Food{Point: game.Point{X: 4, Y: 5}, Kind: game.BonusFood, Value: 10}
+	g := foodGame(t, a, b)
+
+	if err := g.AddFood(game.NewFood(game.Point{X: 3, Y: 5})); err == nil {
+		t.Errorf("Game accepted two foods on the same point")
+	}
+	if err := g.AddFood(game.NewFood(game.Point{X: 10, Y: 5})); err == nil {
+		t.Errorf("Game accepted food outside of the grid")
+	}
+
+	if r := foodTick(t, &g); !r.AteFood || r.Food != a || !r.Grew {
+		t.Errorf("Snake didn't eat the normal food: %+v", r)
+	}
+	if g.NeedsFood() || len(g.Foods()) != 1 {
+		t.Errorf("Game has the wrong food left: %v", g.Foods())
+	}
+	if r := foodTick(t, &g); !r.AteFood || r.Food != b || !r.Grew {
+		t.Errorf("Snake didn't eat the bonus food: %+v", r)
+	}
+	if !g.NeedsFood() {
+		t.Errorf("Game doesn't need food after it was all eaten")
+	}
+	if r, _ := g.Result(0); r.Length != 5 || r.FoodEaten != 2 {
+		t.Errorf("Snake has the wrong result: %+v", r)
+	}
+}
+
+// Test that grow food grows the snake over the following ticks
+func Test_FoodGrow(t *testing.T) {
+	g := foodGame(t, game.Food{Point: game.Point{X: 3, Y: 5}, Kind: game.GrowFood, Value: 3})
+
+	for i := 0; i < 3; i++ {
+		if r := foodTick(t, &g); !r.Grew || r.AteFood != (i == 0) {
+			t.Errorf("Snake didn't grow on tick %d: %+v", i, r)
+		}
+	}
+	if r := foodTick(t, &g); !r.Moved || r.Grew {
+		t.Errorf("Snake kept growing: %+v", r)
+	}
+	if g.Length() != 6 {
+		t.Errorf("Snake grew to the wrong length: %d", g.Length())
+	}
+}
+
+// Test that shrink food shrinks the snake, but never below its head
+func Test_FoodShrink(t *testing.T) {
+	g := foodGame(t, game.Food{Point: game.Point{X: 3, Y: 5}, Kind: game.ShrinkFood, Value: 1}, game.Food{Point: game.Point{X: 4, Y: 5}, Kind: game.ShrinkFood, Value: 5})
+
+	if r := foodTick(t, &g); !r.Moved || r.Shrank != 1 || g.Length() != 2 {
+		t.Errorf("Snake didn't shrink by 1: %+v", r)
+	}
+	if r := foodTick(t, &g); !r.Moved || r.Shrank != 1 || g.Length() != 1 {
+		t.Errorf("Snake didn't shrink to its head: %+v", r)
+	}
+	if g.Head().String() != "(4,5)" {
+		t.Errorf("Shrunk snake is in the wrong place: %s", g.Head())
+	}
+}
+
+// Test that speed food doesn't grow the snake
+func Test_FoodSpeed(t *testing.T) {
+	f := game.Food{Point: game.Point{X: 3, Y: 5}, Kind: game.SpeedFood, Value: -1}
+	g := foodGame(t, f)
+
+	if r := foodTick(t, &g); !r.AteFood || !r.Moved || r.Grew || r.Food != f {
+		t.Errorf("Snake didn't eat speed food: %+v", r)
+	}
+	if g.Length() != 3 {
+		t.Errorf("Speed food changed the snake length: %d", g.Length())
+	}
+}
+
+// Test that food expires at the end of its last tick
+func Test_FoodExpires(t *testing.T) {
+	g := foodGame(t, game.Food{Point: game.Point{X: 9, Y: 0}, Expires: 2}, game.Food{Point: game.Point{X: 4, Y: 5}, Expires: 2})
+
+	foodTick(t, &g)
+	if len(g.Foods()) != 2 {
+		t.Errorf("Food expired early: %v", g.Foods())
+	}
+	if r := foodTick(t, &g); !r.AteFood {
+		t.Errorf("Snake couldn't eat food on its last tick: %+v", r)
+	}
+	if !g.NeedsFood() {
+		t.Errorf("Food didn't expire: %v", g.Foods())
+	}
+}
+
+// Test that food left on the board stops the board being full
+func Test_FoodVictory(t *testing.T) {
+	g, err := game.NewGame(game.Grid{X: 2, Y: 0}, game.NewSnake(game.Point{X: 0, Y: 0}, game.Right), game.Point{X: 1, Y: 0})
+	if err != nil {
+		t.Fatalf("Error creating game: %s", err)
+	}
+	g.AddFood(game.Food{Point: game.Point{X: 2, Y: 0}, Kind: game.GrowFood, Value: 2})
+
+	foodTick(t, &g) // eat (1,0)
+	if !g.Full() || g.Won() {
+		t.Errorf("Game with food left on a full board was won")
+	}
+	if r := foodTick(t, &g); !r.AteFood || !r.Victory || !g.Won() {
+		t.Errorf("Snake didn't win by eating the last food: %+v", r)
+	}
+}
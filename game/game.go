@@ -2,23 +2,27 @@ package game
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 )
 
 /**
  *  A Game tracks the various coordinated components of a game of Snake.  It
- *  Maintains a Grid space for a playing surface, a Snake, and a Food Point.
+ *  Maintains a Grid space for a playing surface, with any Obstacles inside of
+ *  it, some Snakes, and some Food.
  *  The Game is RESPONSIBLE FOR MOVE VALIDATION AND EXECUTION.
  *  That the game needs to implement the following functionality:
  *  1. Validate and run a game Tick, which is clock iteration for moving the
  *     snake forward on its vector.
- *  2. Check if a game Tick produces a Boundary collision or a Snake self Collision
- *  3. Check if a Tick moves a snake onto food, and there grows the snake and
- *     Requires new food.
+ *  2. Check if a game Tick produces a Boundary collision or a Snake Collision,
+ *     either with itself or another snake, or a Head collision between snakes
+ *  3. Check if a Tick moves a snake onto food, and there eats the food (which
+ *     grows the snake for most food kinds) and Requires new food.
+ *  4. Check if a Tick fills the board with snakes, which wins the game
  *
  *
- *  @NOTE We define NO-FOOD as a FOOD point outside of the Grid, in order to
- *    maintain point memory without a reference. An empty point struct is a valid
- *    in grid food Point.
+ *  @NOTE A game with no food left on the board needs food.  The game doesn't
+ *    decide how much food there should be, whatever places the food does.
  */
 
 // Game constructor with more automatic setup
@@ -30,30 +34,166 @@ func AutoGame(size Vector, food Point) (Game, error) {
 	return g, err
 }
 
-// NewGame validating Game constructor
+// NewGame validating Game constructor for a single snake game
 func NewGame(gr Grid, s Snake, f Point) (Game, error) {
-	g := Game{grid: gr, snake: s, food: f}
+	return NewMultiGame(gr, []Snake{s}, f)
+}
+
+// NewMultiGame validating Game constructor for a game with many snakes.  Each
+// snake gets the SnakeID of its position in the passed slice.
+func NewMultiGame(gr Grid, ss []Snake, f Point) (Game, error) {
+	g := Game{grid: gr, snakes: make([]Snake, 0, len(ss)), records: make([]snakeRecord, len(ss)), foods: []Food{NewFood(f)}, scoring: DefaultScoreRules()}
+	for _, s := range ss {
+		g.snakes = append(g.snakes, s.Clone()) // the game must not share a ring with the caller
+	}
+	g.Seed(NewSeed())
 	return g, g.Validate()
 }
 
-// Game object which can manage a grid and a snake
+// SnakeID identifies a snake in a game
+type SnakeID int
+
+// Game object which can manage a grid and some snakes.
+// @NOTE a Game must not be copied by value, as a copy shares its snakes, food and
+//       records with the original, and ticking either changes the other: use
+//       Clone.
 type Game struct {
-	grid  Grid
-	snake Snake
-	food  Point
+	grid     Grid
+	topology Topology // how the grid edges join, walled by default
+	walls    Obstacles
+	snakes   []Snake
+	records  []snakeRecord // how each snake is doing, dead snakes are kept for reporting
+	foods    []Food        // food on the board, in the order that it was placed
+	ticks    uint          // how many ticks have been played
+	rng      Random
+	seed     int64 // the seed that the random numbers started from
+	turns    TurnPolicy
+	scoring  ScoreRules
+	speed    int // the speed level, which speed food changes
+}
+
+// Clone a copy of the game, which shares nothing with the original, so that
+// either can be played without changing the other
+func (g *Game) Clone() Game {
+	c := *g
+	if g.walls != nil {
+		c.walls = Obstacles{}
+		for p := range g.walls {
+			c.walls.Add(p)
+		}
+	}
+	c.snakes = make([]Snake, 0, len(g.snakes))
+	for i := range g.snakes {
+		c.snakes = append(c.snakes, g.snakes[i].Clone())
+	}
+	c.records = append([]snakeRecord{}, g.records...)
+	for i := range c.records {
+		c.records[i].turns = append([]Vector{}, g.records[i].turns...)
+	}
+	c.foods = append([]Food{}, g.foods...)
+	if g.scoring.Food != nil {
+		c.scoring.Food = map[FoodKind]int{}
+		for k, v := range g.scoring.Food {
+			c.scoring.Food[k] = v
+		}
+	}
+	return c
 }
 
 // Validate the game
 func (g *Game) Validate() error {
-	// Validate
+	if len(g.foods) == 0 {
+		return errors.New("Could not create game, it has no food.")
+	}
+	return g.validateBoard()
+}
+
+// validate everything on the board, allowing for the game to have no food (like
+// during play, after food has been eaten)
+func (g *Game) validateBoard() error {
 	if !g.grid.Contains(Point{X: 0, Y: 0}) { // grid is X>0 and Y>0
 		return errors.New("Could not create game, grid isn't `positive`.")
 	}
-	if !g.grid.Contains(g.snake.HeadPoint()) {
-		return errors.New("Could not create game, as Snake head point is outside of grid.")
+	if len(g.snakes) == 0 {
+		return errors.New("Could not create game, as it has no snakes.")
+	}
+	if g.topology > CylinderY {
+		return errors.New("Could not create game, it has an unknown topology.")
+	}
+	if g.turns.Reverse > AllowReverse || g.turns.Buffer < 0 {
+		return errors.New("Could not create game, its turn policy is invalid.")
+	}
+	for _, r := range g.records {
+		if r.end > HitHead {
+			return errors.New("Could not create game, a snake has an unknown end reason.")
+		}
+	}
+	for p := range g.walls {
+		if !g.grid.Contains(p) {
+			return errors.New("Could not create game, an obstacle is outside of the grid.")
+		}
 	}
-	if !g.grid.Contains(g.food) {
-		return errors.New("Could not create game, snake start is outside of the grid.")
+	taken := map[Point]bool{}
+	for _, id := range g.Living() {
+		if err := g.validateSnake(&g.snakes[id], taken); err != nil {
+			return errors.New("Could not create game, " + err.Error())
+		}
+	}
+	for i, f := range g.foods {
+		if err := g.validateFood(f, g.foods[:i]); err != nil {
+			return errors.New("Could not create game, " + err.Error())
+		}
+	}
+	return nil
+}
+
+// validate a living snake: every segment is on the grid, next to the segment
+// before it (across any wrapping edges), and not on an obstacle or on a point that
+// is already taken (by another snake, or by the snake itself)
+func (g *Game) validateSnake(s *Snake, taken map[Point]bool) error {
+	ps := s.Points()
+	for i, p := range ps {
+		if !g.grid.Contains(p) {
+			return errors.New("a snake is outside of the grid.")
+		}
+		if i > 0 && !g.adjacent(ps[i-1], p) {
+			return errors.New("a snake has segments that aren't next to each other.")
+		}
+		if g.walls.Contains(p) {
+			return errors.New("a snake is on an obstacle.")
+		}
+		if taken[p] {
+			return errors.New("two snake segments are on the same point.")
+		}
+		taken[p] = true
+	}
+	return nil
+}
+
+// are two points a single move apart, with a direction that the turn policy
+// allows, across any wrapping edges
+func (g *Game) adjacent(a, b Point) bool {
+	for _, d := range []Vector{Up, Right, Down, Left, UpRight, DownRight, DownLeft, UpLeft} {
+		if g.turns.unit(d) && g.topology.Normalize(g.grid, a.Move(d)).Equals(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// validate a food item against the grid, obstacles and some other food
+func (g *Game) validateFood(f Food, others []Food) error {
+	if !g.grid.Contains(f.Point) {
+		return errors.New("food is outside of the grid.")
+	}
+	if g.walls.Contains(f.Point) {
+		return errors.New("food is on an obstacle.")
+	}
+	if _, ok := foodAt(others, f.Point); ok {
+		return errors.New("two foods are on the same point.")
+	}
+	if f.Kind.String() == "unknown" {
+		return errors.New("food has an unknown kind.")
 	}
 	return nil
 }
@@ -63,84 +203,583 @@ func (g *Game) Size() Vector {
 	return Vector(g.grid)
 }
 
+// SetTopology change how the grid edges join
+func (g *Game) SetTopology(t Topology) {
+	g.topology = t
+}
+
+// Topology of the grid edges
+func (g *Game) Topology() Topology {
+	return g.topology
+}
+
+// SetObstacles block some Points on the grid, as long as the game stays valid
+func (g *Game) SetObstacles(o Obstacles) error {
+	old := g.walls
+	g.walls = o
+	if err := g.validateBoard(); err != nil {
+		g.walls = old
+		return err
+	}
+	return nil
+}
+
+// Obstacles blocking the grid
+func (g *Game) Obstacles() Obstacles {
+	return g.walls
+}
+
+// Blocked is a Point on an obstacle
+func (g *Game) Blocked(p Point) bool {
+	return g.walls.Contains(p)
+}
+
+// Free is a Point inside of the grid, and not taken by an obstacle, food or a
+// living snake.  Food can be placed on a free point.
+func (g *Game) Free(p Point) bool {
+	if !g.grid.Contains(p) || g.walls.Contains(p) {
+		return false
+	}
+	if _, ok := foodAt(g.foods, p); ok {
+		return false
+	}
+	for _, id := range g.Living() {
+		if g.snakes[id].Contains(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrBoardFull there are no free points left on the board
+var ErrBoardFull = errors.New("Board is full, there is nowhere to put food")
+
+// FreeCells all of the free Points in the grid, in row order
+func (g *Game) FreeCells() []Point {
+	taken := map[Point]bool{}
+	for _, f := range g.foods {
+		taken[f.Point] = true
+	}
+	for _, id := range g.Living() {
+		for _, p := range g.snakes[id].Points() {
+			taken[p] = true
+		}
+	}
+
+	ps := []Point{}
+	for y := 0; y <= g.grid.Y; y++ {
+		for x := 0; x <= g.grid.X; x++ {
+			p := Point{X: x, Y: y}
+			if !taken[p] && !g.walls.Contains(p) {
+				ps = append(ps, p)
+			}
+		}
+	}
+	return ps
+}
+
+// FreeCount how many free Points there are in the grid
+// @NOTE this relies on the game being valid, with no snakes, food or obstacles on
+//       top of each other.
+func (g *Game) FreeCount() int {
+	n := (g.grid.X+1)*(g.grid.Y+1) - len(g.walls) - len(g.foods)
+	for _, id := range g.Living() {
+		n -= int(g.snakes[id].Length())
+	}
+	return n
+}
+
+// Full are there no free Points left in the grid
+func (g *Game) Full() bool {
+	return g.FreeCount() <= 0
+}
+
+// Snakes ids of all snakes in the game, living or dead
+func (g *Game) Snakes() []SnakeID {
+	ids := []SnakeID{}
+	for i := range g.snakes {
+		ids = append(ids, SnakeID(i))
+	}
+	return ids
+}
+
+// Living ids of all snakes that are still playing
+func (g *Game) Living() []SnakeID {
+	ids := []SnakeID{}
+	for i := range g.snakes {
+		if g.records[i].end == Playing || g.records[i].end == Victory {
+			ids = append(ids, SnakeID(i))
+		}
+	}
+	return ids
+}
+
+// Alive is a snake still playing
+func (g *Game) Alive(id SnakeID) bool {
+	return g.valid(id) && (g.records[id].end == Playing || g.records[id].end == Victory)
+}
+
+// Won did the snakes fill the board
+func (g *Game) Won() bool {
+	for _, r := range g.records {
+		if r.end == Victory {
+			return true
+		}
+	}
+	return false
+}
+
+// Over is the game over, as it has been won or no snakes are left playing
+func (g *Game) Over() bool {
+	return g.Won() || len(g.Living()) == 0
+}
+
+// Result how a snake has done in the game so far
+func (g *Game) Result(id SnakeID) (GameResult, error) {
+	if !g.valid(id) {
+		return GameResult{}, fmt.Errorf("Game has no snake %d", id)
+	}
+	r := g.records[id]
+	return GameResult{Snake: id, Reason: r.end, Length: g.snakes[id].Length(), Ticks: r.ticks, FoodEaten: r.eaten, Score: r.score.Points}, nil
+}
+
+// Results how every snake has done in the game so far
+func (g *Game) Results() []GameResult {
+	rs := []GameResult{}
+	for _, id := range g.Snakes() {
+		r, _ := g.Result(id)
+		rs = append(rs, r)
+	}
+	return rs
+}
+
+// Snake get a snake by id
+func (g *Game) Snake(id SnakeID) (*Snake, error) {
+	if !g.valid(id) {
+		return nil, fmt.Errorf("Game has no snake %d", id)
+	}
+	return &g.snakes[id], nil
+}
+
+// SetTurnPolicy change which turns the game accepts
+func (g *Game) SetTurnPolicy(tp TurnPolicy) {
+	g.turns = tp
+}
+
+// TurnPolicy which turns the game accepts
+func (g *Game) TurnPolicy() TurnPolicy {
+	return g.turns
+}
+
+// TurnSnake turn a snake to a new direction (Does not step).  Turns that the
+// turn policy rejects return a TurnError, and ignored turns do nothing.
+// Turning to the direction that the snake already faces is accepted.
+// If the turn policy buffers turns then the turn is queued for a later tick.
+func (g *Game) TurnSnake(id SnakeID, d Vector) error {
+	if !g.valid(id) {
+		return TurnError{Snake: id, To: d, Reason: TurnNoSnake}
+	}
+	s, r := &g.snakes[id], &g.records[id]
+	if !g.Alive(id) {
+		return TurnError{Snake: id, From: s.Facing(), To: d, Reason: TurnDeadSnake}
+	}
+
+	// check against the direction the snake will have when the turn is applied:
+	// the way that it last moved (not its facing, which an earlier turn since the
+	// tick may have changed) or its last queued turn
+	from := g.heading(s)
+	if len(r.turns) > 0 {
+		from = r.turns[len(r.turns)-1]
+	}
+	ok, err := g.turns.Check(from, d, s.Length())
+	if te, isTurn := err.(TurnError); isTurn {
+		te.Snake = id
+		return te
+	}
+	if !ok {
+		return nil
+	}
+
+	if g.turns.Buffer <= 0 {
+		s.Turn(d)
+	} else if len(r.turns) >= g.turns.Buffer {
+		return TurnError{Snake: id, From: from, To: d, Reason: TurnQueueFull}
+	} else {
+		r.turns = append(r.turns, d)
+	}
+	return nil
+}
+
+// the direction that a snake last moved in, from its neck to its head, through
+// any wrapping edges.  A snake that is only a head has no neck, so it is the way
+// that the snake faces.
+func (g *Game) heading(s *Snake) Vector {
+	if s.Length() < 2 {
+		return s.Facing()
+	}
+	head, neck := s.at(0), s.at(1)
+	for _, d := range []Vector{Up, Right, Down, Left, UpRight, DownRight, DownLeft, UpLeft} {
+		if g.topology.Normalize(g.grid, neck.Move(d)) == head {
+			return d
+		}
+	}
+	return s.Facing()
+}
+
+// QueuedTurns the turns a snake has waiting for the next ticks
+func (g *Game) QueuedTurns(id SnakeID) []Vector {
+	if !g.valid(id) {
+		return nil
+	}
+	return append([]Vector{}, g.records[id].turns...)
+}
+
+// is there a snake with the id
+func (g *Game) valid(id SnakeID) bool {
+	return id >= 0 && int(id) < len(g.snakes)
+}
+
+/**
+ * The following methods act on the first snake, which is the player in a single
+ * snake game.
+ */
+
 // Turn to a new direction (Does not step)
-// @TODO should we detect turning to the same direction?
-func (g *Game) Turn(d Vector) {
-	g.snake.Turn(d)
+func (g *Game) Turn(d Vector) error {
+	return g.TurnSnake(0, d)
 }
 
 // Facing snake direction
 func (g *Game) Facing() Vector {
-	return g.snake.Facing()
+	return g.snakes[0].Facing()
 }
 
 // Get the Head segment (can be used for recursion)
 func (g *Game) Head() *Segment {
-	return g.snake.Head()
+	return g.snakes[0].Head()
 }
 
 // Get the HeadPoint
 func (g *Game) HeadPoint() Point {
-	return g.snake.HeadPoint()
+	return g.snakes[0].HeadPoint()
 }
 func (g *Game) Length() uint {
-	return g.snake.Length()
+	return g.snakes[0].Length()
+}
+
+// Ticks how many ticks have been played
+func (g *Game) Ticks() uint {
+	return g.ticks
+}
+
+// Seed the game random numbers
+func (g *Game) Seed(seed int64) {
+	g.seed = seed
+	g.rng.Seed(seed)
+}
+
+// RandomSeed the seed that the game random numbers started from
+func (g *Game) RandomSeed() int64 {
+	return g.seed
+}
+
+// Random numbers for the game, which are saved with it
+func (g *Game) Random() *Random {
+	return &g.rng
 }
 
-// Set a Food Point
+// Set a Food Point, as the only (normal) food on the board
 func (g *Game) SetFood(f Point) {
-	g.food = f
+	g.foods = []Food{NewFood(f)}
+}
+
+// AddFood put another item of food on the board
+// @NOTE food can be put under a snake, which will never be able to eat it, so
+//       food makers should only use Free points.
+func (g *Game) AddFood(f Food) error {
+	if err := g.validateFood(f, g.foods); err != nil {
+		return errors.New("Could not add food, " + err.Error())
+	}
+	g.foods = append(g.foods, f)
+	return nil
+}
+
+// RemoveFood take any food off of a Point, saying if there was any
+func (g *Game) RemoveFood(p Point) bool {
+	for i, f := range g.foods {
+		if f.Point.Equals(p) {
+			g.foods = append(g.foods[:i:i], g.foods[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Foods all of the food on the board, in the order that it was placed
+func (g *Game) Foods() []Food {
+	return append([]Food{}, g.foods...)
+}
+
+// FoodAt the food on a Point, if there is any
+func (g *Game) FoodAt(p Point) (Food, bool) {
+	return foodAt(g.foods, p)
 }
 
-// Unset food, like if it was just eaten
-func (g *Game) unsetFood() {
-	g.food = Point{X: g.grid.X + 1, Y: g.grid.Y + 1}
+// find the food on a point in a list of food
+func foodAt(fs []Food, p Point) (Food, bool) {
+	for _, f := range fs {
+		if f.Point.Equals(p) {
+			return f, true
+		}
+	}
+	return Food{}, false
 }
 
-// Get the current Food Point
+// Get the current Food Point, which is the first food placed if there is more
+// than one.  With no food the point is outside of the grid.
 func (g *Game) Food() (Point, error) {
 	if g.NeedsFood() {
-		return g.food, errors.New("Game currently has no food.")
+		return Point{X: g.grid.X + 1, Y: g.grid.Y + 1}, errors.New("Game currently has no food.")
 	}
-	return g.food, nil
+	return g.foods[0].Point, nil
 }
 
-// Does the game need a new food point
+// Does the game need a new food point, as there is none left on the board
 func (g *Game) NeedsFood() bool {
-	return !g.grid.Contains(g.food)
+	return len(g.foods) == 0
+}
+
+// take any expired food off of the board
+func (g *Game) expireFood() {
+	fs := g.foods[:0]
+	for _, f := range g.foods {
+		if !f.Expired(g.ticks) {
+			fs = append(fs, f)
+		}
+	}
+	g.foods = fs
 }
 
-// Tick the game forward as a step
-func (g *Game) Tick() (TickResult, error) {
-	shp := g.snake.HeadPoint()
-	sd := g.snake.Facing()
+// filled have the snakes taken every point on the board, leaving no food
+func (g *Game) filled() bool {
+	return len(g.foods) == 0 && g.Full()
+}
+
+// Tick the game forward as a step, for every living snake at once
+//
+// Each snake head moves to its next point, wrapped by the grid topology.
+// Collisions are all decided against the board as it was before the tick, so
+// the order of the snakes doesn't matter:
+//  1. a head leaving the grid (across a wall) is a boundary collision, and a
+//     head moving onto an obstacle is an obstacle collision
+//  2. two heads moving onto the same point, or swapping points, is a head
+//     collision for both snakes.  Two snakes reaching for the same food is
+//     therefore a head collision, and nobody eats.
+//  3. a head moving onto any snake point (its own included) is a snake collision
+//
+// Snakes that collide don't move and are dead for the rest of the game.  A snake
+// moving onto food eats it, with the effect of its kind, and any food past its
+// expiry tick is then taken off the board.  The snakes that survived are scored
+// by the game ScoreRules.  If the living snakes grow to fill the
+// board then they have won, and the game is over.
+func (g *Game) Tick() (TickResults, error) {
+	live := g.Living()
+	if len(live) == 0 {
+		return TickResults{}, errors.New("No snakes left alive")
+	}
+	if g.Won() {
+		return TickResults{}, errors.New("The game has already been won")
+	}
+
+	nps := map[SnakeID]Point{}
+	for _, id := range live {
+		// apply one queued turn per tick
+		if r := &g.records[id]; len(r.turns) > 0 {
+			g.snakes[id].Turn(r.turns[0])
+			r.turns = r.turns[1:]
+		}
+
+		s := g.snakes[id]
+		hp := s.HeadPoint()
+		nps[id] = g.topology.Normalize(g.grid, hp.Move(s.Facing()))
+	}
+
+	g.ticks++
+
+	rs := TickResults{}
+	errs := []string{}
+	for _, id := range live {
+		r := g.collide(id, live, nps)
+
+		// Prevent a Move if that will cause a collision, and report an error
+		if r.BoundaryCollision {
+			errs = append(errs, g.collisionError(id, "Grid collision"))
+		} else if r.ObstacleCollision {
+			errs = append(errs, g.collisionError(id, "Obstacle collision"))
+		} else if r.HeadCollision {
+			errs = append(errs, g.collisionError(id, "Head Collision"))
+		} else if r.SnakeCollision {
+			errs = append(errs, g.collisionError(id, "Snake Collision"))
+		}
+		rs = append(rs, r)
+	}
+
+	grew := false
+	for i, r := range rs {
+		rec := &g.records[r.Snake]
+		if r.Collided() {
+			rec.end = r.endReason()
+			continue
+		}
 
-	np := shp.Move(sd)
+		shrink := uint(0)
+		if f, ok := g.FoodAt(nps[r.Snake]); ok {
+			g.RemoveFood(f.Point)
+			rs[i].AteFood, rs[i].Food = true, f
+			rec.eaten++
+			if n := f.Growth(); n > 0 {
+				rec.grow += uint(n)
+			} else if n < 0 {
+				shrink = rec.shrinkGrowth(uint(-n))
+			}
+			if f.Kind == SpeedFood {
+				g.speed += f.Value
+			}
+		}
 
-	// Prevent a Move if that will cause a collision, and return an error
+		if rec.grow > 0 {
+			g.snakes[r.Snake].GrowTo(nps[r.Snake])
+			rec.grow--
+			rs[i].Grew = true
+			grew = true
+		} else {
+			g.snakes[r.Snake].AdvanceTo(nps[r.Snake])
+			rs[i].Moved = true
+			rs[i].Shrank = g.snakes[r.Snake].Shrink(shrink)
+		}
+		rec.ticks++
+	}
+	g.expireFood()
+
+	// score the snakes that survived, on the board as it now is
+	for i, r := range rs {
+		if !r.Collided() {
+			rs[i].NearMiss = g.nearMiss(r.Snake)
+			rs[i].Points = g.scoring.score(&g.records[r.Snake].score, rs[i], g.ticks, g.speed)
+		}
+	}
+
+	if grew {
+		// Filling the board is a win for every snake still playing
+		if g.filled() {
+			for i, r := range rs {
+				if !r.Collided() {
+					rs[i].Victory = true
+					g.records[r.Snake].end = Victory
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return rs, errors.New(strings.Join(errs, "; "))
+	}
+	return rs, nil
+}
+
+// find any collision for a snake moving to its next point, when the live snakes
+// move to theirs
+func (g *Game) collide(id SnakeID, live []SnakeID, nps map[SnakeID]Point) TickResult {
+	np := nps[id]
+	hp := g.snakes[id].HeadPoint()
 
 	if !g.grid.Contains(np) {
-		return TickResult{BoundaryCollision: true}, errors.New("Grid collision")
+		return TickResult{Snake: id, BoundaryCollision: true}
+	}
+	if g.walls.Contains(np) {
+		return TickResult{Snake: id, ObstacleCollision: true}
+	}
+	for _, o := range live {
+		if o == id {
+			continue
+		}
+		oh := g.snakes[o].HeadPoint()
+		if np.Equals(nps[o]) || (np.Equals(oh) && hp.Equals(nps[o])) {
+			return TickResult{Snake: id, HeadCollision: true, CollidedWith: o}
+		}
 	}
-	if g.snake.Contains(np) {
-		return TickResult{SnakeCollision: true}, errors.New("Snake Collision")
+	for _, o := range live {
+		if g.snakes[o].Contains(np) {
+			return TickResult{Snake: id, SnakeCollision: true, CollidedWith: o}
+		}
 	}
+	return TickResult{Snake: id}
+}
 
-	if np.Equals(g.food) {
-		g.snake.Grow()
-		g.unsetFood()
-		return TickResult{AteFood: true, Grew: true}, nil
-	} else {
-		g.snake.Advance()
-		return TickResult{Moved: true}, nil
+// keep single snake collision errors as they were, but say who collided if there
+// is more than one snake
+func (g *Game) collisionError(id SnakeID, msg string) string {
+	if len(g.snakes) == 1 {
+		return msg
 	}
+	return fmt.Sprintf("Snake %d: %s", id, msg)
 }
 
-// we could return the results of a step like this
+// we could return the results of a step like this, one per snake
 type TickResult struct {
-	AteFood           bool // Did the snake eat food (to signal that we need new food)
-	Grew              bool // Did the snake grow forward (to signal snake growtch)
-	Moved             bool // did the snake move forward
-	BoundaryCollision bool // Did the snake collide with the boundary
-	SnakeCollision    bool // Did the snake collide with itself (cycle)
+	Snake             SnakeID // Which snake the results are for
+	AteFood           bool    // Did the snake eat food (to signal that we need new food)
+	Food              Food    // The food that the snake ate, if it ate
+	Grew              bool    // Did the snake grow forward (to signal snake growtch)
+	Moved             bool    // did the snake move forward
+	Shrank            uint    // How many tail segments the snake lost to shrink food
+	NearMiss          bool    // Did the snake head end up right next to something it could have hit
+	Points            int     // How many points the snake scored (or lost) on the tick
+	BoundaryCollision bool    // Did the snake collide with the boundary
+	ObstacleCollision bool    // Did the snake collide with an obstacle inside the grid
+	SnakeCollision    bool    // Did the snake collide with a snake body (its own is a cycle)
+	HeadCollision     bool    // Did the snake collide head first with another snake head
+	CollidedWith      SnakeID // The snake that was collided with, if there was a snake or head collision
+	Victory           bool    // Did the snakes fill the board, which wins the game
+}
+
+// Collided did the snake collide with anything
+func (r TickResult) Collided() bool {
+	return r.BoundaryCollision || r.ObstacleCollision || r.SnakeCollision || r.HeadCollision
+}
+
+// TickResults the results of a game tick, for each snake that was alive for it
+type TickResults []TickResult
+
+// Get the result for a snake, if it was alive for the tick
+func (rs TickResults) Get(id SnakeID) (TickResult, bool) {
+	for _, r := range rs {
+		if r.Snake == id {
+			return r, true
+		}
+	}
+	return TickResult{}, false
+}
+
+// Equals are the results all the same
+func (rs TickResults) Equals(os TickResults) bool {
+	if len(rs) != len(os) {
+		return false
+	}
+	for i := range rs {
+		if rs[i] != os[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// AteFood did any snake eat food
+func (rs TickResults) AteFood() bool {
+	for _, r := range rs {
+		if r.AteFood {
+			return true
+		}
+	}
+	return false
 }
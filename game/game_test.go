@@ -217,6 +217,275 @@ func Test_GamePlayBoundaryCollision(t *testing.T) {
 	tg.boundary()
 }
 
+// Test that a snake on a torus passes through the edges of the grid
+func Test_GamePlayTorus(t *testing.T) {
+	tg := testingGame(t)
+	tg.game.SetTopology(game.Torus)
+
+	tg.move(5)          // (5,5) -> (5,10)
+	tg.move(1)          // (5,10) -> (5,0)
+	tg.turn(game.Right) // turn right
+	tg.move(6)          // (5,0) -> (0,0)
+
+	if !tg.game.HeadPoint().Equals(game.Point{X: 0, Y: 0}) {
+		t.Errorf("Snake did not wrap around the torus: %s", tg.game.HeadPoint())
+	}
+}
+
+// Test that a cylinder only wraps along one axis
+func Test_GamePlayCylinder(t *testing.T) {
+	tg := testingGame(t)
+	tg.game.SetTopology(game.CylinderX)
+
+	tg.turn(game.Left) // turn left
+	tg.move(6)         // (5,5) -> (10,5)
+	tg.turn(game.Up)   // turn up
+	tg.move(5)         // (10,5) -> (10,10)
+
+	// Expect a boundary collision on the next step
+	tg.boundary()
+}
+
+// Test that obstacles can't be put under the snake or food
+func Test_GameObstacleValidation(t *testing.T) {
+	tg := testingGame(t) // snake at (5,5) and food at (3,4)
+
+	if err := tg.game.SetObstacles(game.NewObstacles(game.Point{X: 5, Y: 5})); err == nil {
+		t.Errorf("Game accepted an obstacle on the snake")
+	}
+	if err := tg.game.SetObstacles(game.NewObstacles(game.Point{X: 3, Y: 4})); err == nil {
+		t.Errorf("Game accepted an obstacle on the food")
+	}
+	if err := tg.game.SetObstacles(game.NewObstacles(game.Point{X: 11, Y: 4})); err == nil {
+		t.Errorf("Game accepted an obstacle outside of the grid")
+	}
+	if tg.game.Blocked(game.Point{X: 5, Y: 5}) {
+		t.Errorf("Game kept an obstacle that it rejected")
+	}
+	if err := tg.game.SetObstacles(game.NewObstacles(game.Point{X: 5, Y: 8})); err != nil {
+		t.Errorf("Game rejected a valid obstacle: %s", err)
+	}
+	if tg.game.Free(game.Point{X: 5, Y: 8}) || tg.game.Free(game.Point{X: 5, Y: 5}) {
+		t.Errorf("Game reports that a taken point is free")
+	}
+	if !tg.game.Free(game.Point{X: 5, Y: 6}) {
+		t.Errorf("Game reports that an empty point is not free")
+	}
+}
+
+// Test counting the free points on the board
+func Test_GameFreeCells(t *testing.T) {
+	g, err := game.NewGame(game.Grid{X: 2, Y: 1}, game.NewSnake(game.Point{X: 0, Y: 0}, game.Right), game.Point{X: 1, Y: 0})
+	if err != nil {
+		t.Errorf("Error creating game: %s", err)
+	}
+	if err := g.SetObstacles(game.NewObstacles(game.Point{X: 2, Y: 1})); err != nil {
+		t.Errorf("Game rejected a valid obstacle: %s", err)
+	}
+	g.Tick() // eat (1,0)
+
+	fs := g.FreeCells()
+	if len(fs) != 3 || g.FreeCount() != 3 {
+		t.Errorf("Game has the wrong free points: %v", fs)
+	}
+	for _, f := range fs {
+		if !g.Free(f) {
+			t.Errorf("Game free points include a point that isn't free: %s", f)
+		}
+	}
+	if g.Full() {
+		t.Errorf("Game reports that the board is full when it isn't")
+	}
+
+	g.SetFood(game.Point{X: 2, Y: 0})
+	g.Tick() // eat (2,0)
+	g.Turn(game.Up)
+	g.SetFood(game.Point{X: 2, Y: 1})
+	if _, err := g.Tick(); err == nil {
+		t.Errorf("Snake did not collide with an obstacle")
+	}
+	g.SetFood(game.Point{X: 0, Y: 1})
+	if g.FreeCount() != 4 || g.Free(game.Point{X: 0, Y: 1}) { // food takes a point too
+		t.Errorf("Dead snake points were not freed: %v", g.FreeCells())
+	}
+}
+
+// Test winning a game by filling the board
+func Test_GameVictory(t *testing.T) {
+	g, err := game.NewGame(game.Grid{X: 2, Y: 0}, game.NewSnake(game.Point{X: 0, Y: 0}, game.Right), game.Point{X: 1, Y: 0})
+	if err != nil {
+		t.Errorf("Error creating game: %s", err)
+	}
+
+	if rs, _ := g.Tick(); rs[0].Victory || g.Over() {
+		t.Errorf("Game was won before the board was full")
+	}
+	g.SetFood(game.Point{X: 2, Y: 0})
+	rs, err := g.Tick()
+	if err != nil {
+		t.Errorf("Winning tick produced an unexpected error: %s", err)
+	}
+	if !rs[0].Victory || !g.Won() || !g.Over() {
+		t.Errorf("Game was not won when the board was full")
+	}
+	if _, err := g.Tick(); err == nil {
+		t.Errorf("Game kept playing after it was won")
+	}
+
+	if r, _ := g.Result(0); r.Reason != game.Victory || r.Length != 3 || r.Ticks != 2 || r.FoodEaten != 2 {
+		t.Errorf("Game had the wrong result for a win: %+v", r)
+	}
+}
+
+// Test the result of a game that ended in a collision
+func Test_GameResultCollision(t *testing.T) {
+	tg := testingGame(t)
+	tg.food(5, 7) // food at (5,7)
+	tg.move(1)    // (5,5) -> (5,6)
+	tg.eat()      // (5,6) -> (5,7) [2]
+	tg.food(1, 1) // food at (1,1)
+	tg.move(3)    // (5,7) -> (5,10)
+	tg.boundary()
+
+	if r, _ := tg.game.Result(0); r.Reason != game.HitBoundary || r.Length != 2 || r.Ticks != 5 || r.FoodEaten != 1 {
+		t.Errorf("Game had the wrong result for a boundary collision: %+v", r)
+	}
+	if !tg.game.Over() || tg.game.Won() {
+		t.Errorf("Game did not end with a collision")
+	}
+}
+
+// Test running into an obstacle
+func Test_GamePlayObstacleCollision(t *testing.T) {
+	tg := testingGame(t)
+	if err := tg.game.SetObstacles(game.NewObstacles(game.Point{X: 5, Y: 8}, game.Point{X: 6, Y: 8})); err != nil {
+		t.Errorf("Game rejected a valid obstacle: %s", err)
+	}
+
+	tg.move(2) // (5,5) -> (5,7)
+
+	// Expect an obstacle collision on the next step
+	tg.obstacle()
+}
+
+// Test that a multi snake game won't start with snakes on top of each other
+func Test_MultiGameConstruct(t *testing.T) {
+	gr := game.Grid{X: 10, Y: 10}
+	f := game.Point{X: 1, Y: 1}
+
+	if g, err := game.NewMultiGame(gr, []game.Snake{
+		game.NewSnake(game.Point{X: 2, Y: 5}, game.Right),
+		game.NewSnake(game.Point{X: 7, Y: 5}, game.Left),
+	}, f); err != nil {
+		t.Errorf("Error creating multi snake game: %s", err)
+	} else if len(g.Snakes()) != 2 || len(g.Living()) != 2 {
+		t.Errorf("Multi snake game does not report both snakes")
+	}
+
+	if _, err := game.NewMultiGame(gr, []game.Snake{
+		game.NewSnake(game.Point{X: 2, Y: 5}, game.Right),
+		game.NewSnake(game.Point{X: 2, Y: 5}, game.Left),
+	}, f); err == nil {
+		t.Errorf("Multi snake game constructor did not produce an error for overlapping snakes")
+	}
+
+	if _, err := game.NewMultiGame(gr, []game.Snake{}, f); err == nil {
+		t.Errorf("Multi snake game constructor did not produce an error for no snakes")
+	}
+}
+
+// Test a snake running into the body of another snake
+func Test_MultiGameHeadToBody(t *testing.T) {
+	b := game.NewSnake(game.Point{X: 4, Y: 2}, game.Up)
+	b.Grow() // (4,3)
+	b.Grow() // (4,4)
+	g := multiGame(t, game.NewSnake(game.Point{X: 3, Y: 3}, game.Right), b)
+
+	rs, err := g.Tick()
+	if err == nil {
+		t.Errorf("Tick with a snake collision did not produce an error")
+	}
+	if r, _ := rs.Get(0); !r.SnakeCollision || r.CollidedWith != 1 {
+		t.Errorf("Snake running into another snake body did not collide with it: %+v", r)
+	}
+	if r, _ := rs.Get(1); !r.Moved || r.Collided() {
+		t.Errorf("Snake that was run into did not move on as normal: %+v", r)
+	}
+	if g.Alive(0) || !g.Alive(1) {
+		t.Errorf("Game has the wrong snakes alive after a collision")
+	}
+
+	// only the living snake plays on
+	rs, err = g.Tick()
+	if err != nil {
+		t.Errorf("Tick after a collision produced an unexpected error: %s", err)
+	} else if _, ok := rs.Get(0); ok {
+		t.Errorf("Dead snake still produced a tick result")
+	} else if r, _ := rs.Get(1); !r.Moved {
+		t.Errorf("Living snake did not move after another snake died")
+	}
+}
+
+// Test two snakes moving onto the same point
+func Test_MultiGameHeadToHead(t *testing.T) {
+	g := multiGame(t,
+		game.NewSnake(game.Point{X: 2, Y: 5}, game.Right),
+		game.NewSnake(game.Point{X: 4, Y: 5}, game.Left))
+	expectHeadCollision(t, &g)
+}
+
+// Test two snakes swapping points, which would pass through each other
+func Test_MultiGameHeadSwap(t *testing.T) {
+	g := multiGame(t,
+		game.NewSnake(game.Point{X: 2, Y: 5}, game.Right),
+		game.NewSnake(game.Point{X: 3, Y: 5}, game.Left))
+	expectHeadCollision(t, &g)
+}
+
+// Test two snakes reaching for the same food at once
+func Test_MultiGameFoodContest(t *testing.T) {
+	g := multiGame(t,
+		game.NewSnake(game.Point{X: 2, Y: 5}, game.Right),
+		game.NewSnake(game.Point{X: 4, Y: 5}, game.Left))
+	g.SetFood(game.Point{X: 3, Y: 5})
+	rs := expectHeadCollision(t, &g)
+
+	if rs.AteFood() {
+		t.Errorf("A snake ate food that was contested")
+	}
+	if g.NeedsFood() {
+		t.Errorf("Contested food was removed from the game")
+	}
+}
+
+// make a game from some snakes, with food out of the way
+func multiGame(t *testing.T, ss ...game.Snake) game.Game {
+	g, err := game.NewMultiGame(game.Grid{X: 10, Y: 10}, ss, game.Point{X: 9, Y: 9})
+	if err != nil {
+		t.Errorf("Error creating multi snake game: %s", err)
+	}
+	return g
+}
+
+// tick a game with two snakes and expect them to collide head first
+func expectHeadCollision(t *testing.T, g *game.Game) game.TickResults {
+	rs, err := g.Tick()
+	if err == nil {
+		t.Errorf("Tick with a head collision did not produce an error")
+	}
+	for _, id := range []game.SnakeID{0, 1} {
+		if r, ok := rs.Get(id); !ok {
+			t.Errorf("Snake %d had no tick result", id)
+		} else if !r.HeadCollision || r.CollidedWith == id {
+			t.Errorf("Snake %d did not report a head collision: %+v", id, r)
+		}
+	}
+	if len(g.Living()) != 0 {
+		t.Errorf("Snakes survived a head collision")
+	}
+	return rs
+}
+
 /**
  * Some tools used to make gmae path testing more straightforward
  */
@@ -273,6 +542,11 @@ func (tg TestingGame) boundary() {
 	processTick(tickAction{Result: game.TickResult{BoundaryCollision: true}, HasError: true}, tg.game, tg.t)
 }
 
+// Move and expect an obstacle collision
+func (tg TestingGame) obstacle() {
+	processTick(tickAction{Result: game.TickResult{ObstacleCollision: true}, HasError: true}, tg.game, tg.t)
+}
+
 // Process test actions as individual logged actions
 
 // Process a slice of actions
@@ -307,12 +581,18 @@ func processFood(a foodAction, g *game.Game, t *testing.T) error {
 
 // Use a standard function for ticking the game, and logging the game status
 func processTick(a tickAction, g *game.Game, t *testing.T) (game.TickResult, error) {
-	res, err := g.Tick()
+	rs, err := g.Tick()
+	res, ok := rs.Get(0)
+	if !ok {
+		t.Errorf("Tick did not produce a result for the snake")
+	}
 	aRes := a.Result
 
 	resString := "unknown"
 	if a.HasError && aRes.BoundaryCollision {
 		resString = "EXPECTED BOUNDARY COLLISION"
+	} else if a.HasError && aRes.ObstacleCollision {
+		resString = "EXPECTED OBSTACLE COLLISION"
 	} else if a.HasError && aRes.SnakeCollision {
 		resString = "EXPECTED SNAKE COLLISION"
 	} else if a.HasError {
@@ -338,6 +618,13 @@ func processTick(a tickAction, g *game.Game, t *testing.T) (game.TickResult, err
 			t.Errorf("Unexpected boundary collision detected")
 		}
 	}
+	if aRes.ObstacleCollision != res.ObstacleCollision {
+		if aRes.ObstacleCollision {
+			t.Errorf("Expected obstacle collision not detected")
+		} else {
+			t.Errorf("Unexpected obstacle collision detected")
+		}
+	}
 	if aRes.SnakeCollision != res.SnakeCollision {
 		if aRes.SnakeCollision {
 			t.Errorf("Expected snake collision not detected")
@@ -370,3 +657,44 @@ func processTick(a tickAction, g *game.Game, t *testing.T) (game.TickResult, err
 	t.Logf("TICK: %s [Direction: %s][Snake: [%v] %s]", resString, g.Facing(), g.Length(), g.Head())
 	return res, err
 }
+
+// a game with one long snake, which can tick straight up for a long time
+func longSnakeGame(b *testing.B) game.Game {
+	s := long_snake(20000, 100)
+	s.Turn(game.Up)
+	g, err := game.NewGame(game.Grid{X: 99, Y: 99999}, s, game.Point{X: 50, Y: 99999})
+	if err != nil {
+		b.Fatalf("Could not create long snake game: %s", err)
+	}
+	return g
+}
+
+func BenchmarkGameTick(b *testing.B) {
+	g := longSnakeGame(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.Tick(); err != nil {
+			b.StopTimer()
+			g = longSnakeGame(b)
+			b.StartTimer()
+		}
+	}
+}
+
+// Test that a cloned game plays without changing the original
+func Test_GameClone(t *testing.T) {
+	tg := testingGame(t)
+	tg.game.SetTurnPolicy(game.TurnPolicy{Buffer: 2})
+	tg.game.Turn(game.Left)
+	c := tg.game.Clone()
+
+	c.Tick()
+	c.Turn(game.Down)
+	c.AddFood(game.NewFood(game.Point{X: 1, Y: 1}))
+	if tg.game.Ticks() != 0 || !tg.game.HeadPoint().Equals(game.Point{X: 5, Y: 5}) || len(tg.game.QueuedTurns(0)) != 1 || len(tg.game.Foods()) != 1 {
+		t.Errorf("Playing a clone changed the original game: %s", tg.game.Head())
+	}
+	if c.Ticks() != 1 || !c.HeadPoint().Equals(game.Point{X: 4, Y: 5}) {
+		t.Errorf("Clone did not play: %s", c.Head())
+	}
+}
@@ -0,0 +1,522 @@
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/**
+ * Levels are a plain text description of a game, so that starting positions can
+ * be designed without writing any go.
+ *
+ * A level is a small header of keyword lines, followed by a `map` line and then
+ * the map itself as ASCII art rows.  Lines starting with # are comments.
+ *
+ *   # A small box with a wall in the middle
+ *   size 9 4                 grid size (the largest X and Y)
+ *   topology walled          walled, torus, cylinder-x or cylinder-y (optional)
+ *   facing up                facing for snakes drawn on the map (optional)
+ *   snake right 2,1 1,1      a snake facing right, points from head to tail
+ *   food 7,3                 a food point, optionally with a kind, value and expiry
+ *   wall 0,0                 a single wall point
+ *   map
+ *   .......F..
+ *   ....#.....
+ *   .S..#.....
+ *   ..........
+ *
+ * The map has a row for every Y (the top row is the largest Y, as Up is +Y) and a
+ * character for every X:
+ *   .  empty
+ *   #  wall
+ *   F  normal food
+ *   S  a new snake head, facing the header facing
+ *
+ * A level can have any number of food items, and needs at least one.  Header food
+ * can have a kind (normal, bonus, grow, shrink or speed), then a value and then
+ * the tick that it expires on, like `food 3,3 grow 4 100`.  A level always starts
+ * on tick 0, so a game written mid-way has its food expiry written as ticks from
+ * now, and it expires at the same point in the game when it is read back.
+ *
+ * Directions are up, down, left and right, or any x,y vector.  Snakes get their
+ * ids in order: header snakes first, and then map snakes from the top left.  Each
+ * header snake point must be next to the point before it (across any wrapping
+ * edges), and a snake can't face back into its own neck.
+ */
+
+// LevelError a problem with a level, and where in the level it was found
+type LevelError struct {
+	Line   int // line number, counting from 1
+	Column int // column number, counting from 1 (0 if it is about the whole line)
+	Msg    string
+}
+
+// Error as a string
+func (e LevelError) Error() string {
+	if e.Column == 0 {
+		return fmt.Sprintf("level line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("level line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// LoadLevel read a level file into a new Game
+func LoadLevel(path string) (Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Game{}, err
+	}
+	defer f.Close()
+	return ReadLevel(f)
+}
+
+// SaveLevel write a game to a level file
+func SaveLevel(path string, g *Game) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteLevel(f, g); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// named directions for levels
+var levelDirections = []struct {
+	name string
+	dir  Vector
+}{
+	{"up", Up},
+	{"down", Down},
+	{"left", Left},
+	{"right", Right},
+}
+
+// a token in a level line, with its column
+type levelField struct {
+	text string
+	col  int
+}
+
+// level parsing state
+type levelReader struct {
+	line   int
+	size   Vector
+	sized  bool
+	topo   Topology
+	facing Vector
+	snakes []Snake
+	lines  []levelSnake // the header snakes, to check once the topology is known
+	foods  []Food
+	walls  Obstacles
+	taken  map[Point]bool // points that are used by a snake, food or wall
+}
+
+// a header snake line, with the fields of its direction and points
+type levelSnake struct {
+	line   int
+	fields []levelField
+}
+
+// ReadLevel parse a level into a new validated Game
+func ReadLevel(r io.Reader) (Game, error) {
+	lr := levelReader{facing: Up, walls: Obstacles{}, taken: map[Point]bool{}}
+	sc := bufio.NewScanner(r)
+
+	mapped := false
+	for sc.Scan() {
+		lr.line++
+		l := strings.TrimRight(sc.Text(), " \t\r")
+		if strings.HasPrefix(strings.TrimSpace(l), "#") || strings.TrimSpace(l) == "" {
+			continue
+		}
+		fs := levelFields(l)
+		if fs[0].text == "map" {
+			if !lr.sized {
+				return Game{}, lr.errorf(fs[0].col, "the map must come after the size")
+			}
+			mapped = true
+			break
+		}
+		if err := lr.header(fs); err != nil {
+			return Game{}, err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return Game{}, err
+	}
+	for i, ls := range lr.lines {
+		if err := lr.checkSnake(lr.snakes[i], ls); err != nil {
+			return Game{}, err
+		}
+	}
+	if !mapped {
+		return Game{}, lr.errorf(0, "level has no map")
+	}
+
+	y := lr.size.Y
+	for sc.Scan() {
+		lr.line++
+		l := strings.TrimRight(sc.Text(), " \t\r")
+		if y < 0 {
+			if l != "" {
+				return Game{}, lr.errorf(1, "map has too many rows, expected %d", lr.size.Y+1)
+			}
+			continue
+		}
+		if err := lr.row(l, y); err != nil {
+			return Game{}, err
+		}
+		y--
+	}
+	if err := sc.Err(); err != nil {
+		return Game{}, err
+	}
+	if y >= 0 {
+		return Game{}, lr.errorf(0, "map has too few rows, expected %d", lr.size.Y+1)
+	}
+	if len(lr.foods) == 0 {
+		return Game{}, lr.errorf(0, "level has no food")
+	}
+	if len(lr.snakes) == 0 {
+		return Game{}, lr.errorf(0, "level has no snakes")
+	}
+
+	g, err := NewMultiGame(Grid(lr.size), lr.snakes, lr.foods[0].Point)
+	if err != nil {
+		return g, err
+	}
+	g.foods = lr.foods
+	g.SetTopology(lr.topo)
+	if err := g.SetObstacles(lr.walls); err != nil {
+		return g, err
+	}
+	return g, nil
+}
+
+// parse a header line
+func (lr *levelReader) header(fs []levelField) error {
+	key, args := fs[0], fs[1:]
+
+	switch key.text {
+	case "size":
+		if len(args) != 2 {
+			return lr.errorf(key.col, "size needs an X and a Y")
+		}
+		if lr.sized {
+			return lr.errorf(key.col, "size was already set")
+		}
+		for i, a := range args {
+			n, err := strconv.Atoi(a.text)
+			if err != nil || n < 0 {
+				return lr.errorf(a.col, "bad size %q", a.text)
+			}
+			if i == 0 {
+				lr.size.X = n
+			} else {
+				lr.size.Y = n
+			}
+		}
+		lr.sized = true
+	case "topology":
+		if len(args) != 1 {
+			return lr.errorf(key.col, "topology needs a name")
+		}
+		t, ok := parseTopology(args[0].text)
+		if !ok {
+			return lr.errorf(args[0].col, "unknown topology %q", args[0].text)
+		}
+		lr.topo = t
+	case "facing":
+		if len(args) != 1 {
+			return lr.errorf(key.col, "facing needs a direction")
+		}
+		d, err := ParseDirection(args[0].text)
+		if err != nil {
+			return lr.errorf(args[0].col, "%s", err)
+		}
+		lr.facing = d
+	case "snake":
+		if len(args) < 2 {
+			return lr.errorf(key.col, "snake needs a direction and at least one point")
+		}
+		d, err := ParseDirection(args[0].text)
+		if err != nil {
+			return lr.errorf(args[0].col, "%s", err)
+		}
+		ps := []Point{}
+		for _, a := range args[1:] {
+			p, err := lr.point(a)
+			if err != nil {
+				return err
+			}
+			if err := lr.take(p, a.col); err != nil {
+				return err
+			}
+			ps = append(ps, p)
+		}
+		lr.snakes = append(lr.snakes, snakeFromPoints(ps, d))
+		lr.lines = append(lr.lines, levelSnake{line: lr.line, fields: args})
+	case "food":
+		if len(args) < 1 || len(args) > 4 {
+			return lr.errorf(key.col, "food needs one point, and then an optional kind, value and expiry")
+		}
+		p, err := lr.point(args[0])
+		if err != nil {
+			return err
+		}
+		f := NewFood(p)
+		if len(args) > 1 {
+			k, ok := parseFoodKind(args[1].text)
+			if !ok {
+				return lr.errorf(args[1].col, "unknown food kind %q", args[1].text)
+			}
+			f.Kind = k
+		}
+		if len(args) > 2 {
+			n, err := strconv.Atoi(args[2].text)
+			if err != nil {
+				return lr.errorf(args[2].col, "bad food value %q", args[2].text)
+			}
+			f.Value = n
+		}
+		if len(args) > 3 {
+			n, err := strconv.Atoi(args[3].text)
+			if err != nil || n < 0 {
+				return lr.errorf(args[3].col, "bad food expiry %q", args[3].text)
+			}
+			f.Expires = uint(n)
+		}
+		return lr.addFood(f, args[0].col)
+	case "wall":
+		for _, a := range args {
+			p, err := lr.point(a)
+			if err != nil {
+				return err
+			}
+			if err := lr.take(p, a.col); err != nil {
+				return err
+			}
+			lr.walls.Add(p)
+		}
+	default:
+		return lr.errorf(key.col, "unknown keyword %q", key.text)
+	}
+	return nil
+}
+
+// parse a map row
+func (lr *levelReader) row(l string, y int) error {
+	if len(l) != lr.size.X+1 {
+		return lr.errorf(0, "map row has %d columns, expected %d", len(l), lr.size.X+1)
+	}
+	for x, c := range []byte(l) {
+		p := Point{X: x, Y: y}
+		switch c {
+		case '.':
+		case '#':
+			if err := lr.take(p, x+1); err != nil {
+				return err
+			}
+			lr.walls.Add(p)
+		case 'F':
+			if err := lr.addFood(NewFood(p), x+1); err != nil {
+				return err
+			}
+		case 'S':
+			if err := lr.take(p, x+1); err != nil {
+				return err
+			}
+			lr.snakes = append(lr.snakes, NewSnake(p, lr.facing))
+		default:
+			return lr.errorf(x+1, "unknown map character %q", c)
+		}
+	}
+	return nil
+}
+
+// check that a header snake has its points in a line, each next to the one before
+// it (across any wrapping edges), and that it doesn't face back into its neck
+func (lr *levelReader) checkSnake(s Snake, ls levelSnake) error {
+	grid := Grid(lr.size)
+	ps := s.Points()
+	for i := 1; i < len(ps); i++ {
+		next := false
+		for _, d := range []Vector{Up, Right, Down, Left} {
+			if lr.topo.Normalize(grid, ps[i-1].Move(d)).Equals(ps[i]) {
+				next = true
+			}
+		}
+		if !next {
+			return LevelError{Line: ls.line, Column: ls.fields[i+1].col, Msg: fmt.Sprintf("snake point %s isn't next to %s", ps[i], ps[i-1])}
+		}
+	}
+	if len(ps) > 1 && lr.topo.Normalize(grid, ps[0].Move(s.Facing())).Equals(ps[1]) {
+		return LevelError{Line: ls.line, Column: ls.fields[0].col, Msg: fmt.Sprintf("snake faces %s, back into its neck", ls.fields[0].text)}
+	}
+	return nil
+}
+
+// parse a point in the grid
+func (lr *levelReader) point(f levelField) (Point, error) {
+	if !lr.sized {
+		return Point{}, lr.errorf(f.col, "points must come after the size")
+	}
+	v, err := parseVector(f.text)
+	if err != nil {
+		return Point{}, lr.errorf(f.col, "%s", err)
+	}
+	p := Point(v)
+	if !Grid(lr.size).Contains(p) {
+		return p, lr.errorf(f.col, "point %s is outside of the grid", p)
+	}
+	return p, nil
+}
+
+// add an item of food
+func (lr *levelReader) addFood(f Food, col int) error {
+	if err := lr.take(f.Point, col); err != nil {
+		return err
+	}
+	lr.foods = append(lr.foods, f)
+	return nil
+}
+
+// mark a point as used, so that nothing else starts on it
+func (lr *levelReader) take(p Point, col int) error {
+	if lr.taken[p] {
+		return lr.errorf(col, "point %s is already used", p)
+	}
+	lr.taken[p] = true
+	return nil
+}
+
+// make an error for the current line
+func (lr *levelReader) errorf(col int, format string, a ...interface{}) error {
+	return LevelError{Line: lr.line, Column: col, Msg: fmt.Sprintf(format, a...)}
+}
+
+// split a line into fields, remembering the column of each
+func levelFields(l string) []levelField {
+	fs := []levelField{}
+	start := -1
+	for i := 0; i <= len(l); i++ {
+		if i == len(l) || l[i] == ' ' || l[i] == '\t' {
+			if start >= 0 {
+				fs = append(fs, levelField{text: l[start:i], col: start + 1})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	return fs
+}
+
+// parse an x,y vector
+func parseVector(s string) (Vector, error) {
+	xy := strings.Split(s, ",")
+	if len(xy) != 2 {
+		return Vector{}, fmt.Errorf("bad point %q, expected x,y", s)
+	}
+	x, errX := strconv.Atoi(xy[0])
+	y, errY := strconv.Atoi(xy[1])
+	if errX != nil || errY != nil {
+		return Vector{}, fmt.Errorf("bad point %q, expected x,y", s)
+	}
+	return Vector{X: x, Y: y}, nil
+}
+
+// ParseDirection a named direction (up, down, left or right), or an x,y vector
+func ParseDirection(s string) (Vector, error) {
+	for _, d := range levelDirections {
+		if d.name == s {
+			return d.dir, nil
+		}
+	}
+	if v, err := parseVector(s); err == nil {
+		return v, nil
+	}
+	return Vector{}, fmt.Errorf("bad direction %q", s)
+}
+
+// parse a topology by its name
+func parseTopology(s string) (Topology, bool) {
+	for _, t := range []Topology{Walled, Torus, CylinderX, CylinderY} {
+		if t.String() == s {
+			return t, true
+		}
+	}
+	return Walled, false
+}
+
+// the tick that food expires on in a level that starts on this tick, which is
+// never 0 (that would be food that doesn't expire)
+func levelExpiry(f Food, tick uint) uint {
+	if f.Expires <= tick {
+		return 1
+	}
+	return f.Expires - tick
+}
+
+// WriteLevel write a game as a level.  Only the living snakes are written, and a
+// game that needs food is written without any, which won't read back until food
+// is added.  All of the food is written in the header, so that its kind is kept,
+// with its expiry counted from the current tick.
+func WriteLevel(w io.Writer, g *Game) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "size %d %d\n", g.grid.X, g.grid.Y)
+	fmt.Fprintf(bw, "topology %s\n", g.topology)
+	for _, id := range g.Living() {
+		s := g.snakes[id]
+		fmt.Fprintf(bw, "snake %s", DirectionName(s.Facing()))
+		for _, p := range s.Points() {
+			fmt.Fprintf(bw, " %d,%d", p.X, p.Y)
+		}
+		fmt.Fprintln(bw)
+	}
+	for _, f := range g.foods {
+		fmt.Fprintf(bw, "food %d,%d", f.Point.X, f.Point.Y)
+		switch {
+		case f.Expires > 0:
+			fmt.Fprintf(bw, " %s %d %d", f.Kind, f.Value, levelExpiry(f, g.ticks))
+		case f.Value != 0:
+			fmt.Fprintf(bw, " %s %d", f.Kind, f.Value)
+		case f.Kind != NormalFood:
+			fmt.Fprintf(bw, " %s", f.Kind)
+		}
+		fmt.Fprintln(bw)
+	}
+
+	fmt.Fprintln(bw, "map")
+	for y := g.grid.Y; y >= 0; y-- {
+		row := make([]byte, g.grid.X+1)
+		for x := range row {
+			if g.walls.Contains(Point{X: x, Y: y}) {
+				row[x] = '#'
+			} else {
+				row[x] = '.'
+			}
+		}
+		bw.Write(row)
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
+
+// DirectionName the name for a direction, falling back to the x,y vector
+func DirectionName(v Vector) string {
+	for _, d := range levelDirections {
+		if d.dir.Equals(v) {
+			return d.name
+		}
+	}
+	return fmt.Sprintf("%d,%d", v.X, v.Y)
+}
@@ -0,0 +1,131 @@
+package game_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/james-nesbitt/snake/game"
+	"strings"
+	"testing"
+)
+
+var testLevel = `# A small box with a wall in the middle
+size 9 4
+topology torus
+snake right 2,1 1,1
+food 0,0 grow 3 50
+map
+.......F..
+....#.....
+.S..#.....
+..........
+..........
+`
+
+// Test reading a level into a game
+func Test_ReadLevel(t *testing.T) {
+	g, err := game.ReadLevel(strings.NewReader(testLevel))
+	if err != nil {
+		t.Fatalf("Error reading level: %s", err)
+	}
+
+	if !g.Size().Equals(game.Vector{X: 9, Y: 4}) {
+		t.Errorf("Level had the wrong size: %s", g.Size())
+	}
+	if g.Topology() != game.Torus {
+		t.Errorf("Level had the wrong topology: %s", g.Topology())
+	}
+	if f, ok := g.FoodAt(game.Point{X: 7, Y: 4}); !ok || f.Kind != game.NormalFood {
+		t.Errorf("Level had the wrong map food: %s", f)
+	}
+	if f, ok := g.FoodAt(game.Point{X: 0, Y: 0}); !ok || f.Kind != game.GrowFood || f.Value != 3 || f.Expires != 50 {
+		t.Errorf("Level had the wrong header food: %s", f)
+	}
+	if len(g.Foods()) != 2 {
+		t.Errorf("Level had the wrong amount of food: %v", g.Foods())
+	}
+	if !g.Blocked(game.Point{X: 4, Y: 3}) || !g.Blocked(game.Point{X: 4, Y: 2}) {
+		t.Errorf("Level did not have its walls")
+	}
+	if len(g.Snakes()) != 2 {
+		t.Fatalf("Level had the wrong number of snakes: %d", len(g.Snakes()))
+	}
+
+	s, _ := g.Snake(0)
+	if s.Length() != 2 || !s.HeadPoint().Equals(game.Point{X: 2, Y: 1}) || s.Facing() != game.Right {
+		t.Errorf("Header snake was not read properly: %s facing %s", s.Head(), s.Facing())
+	}
+	s, _ = g.Snake(1)
+	if s.Length() != 1 || !s.HeadPoint().Equals(game.Point{X: 1, Y: 2}) || s.Facing() != game.Up {
+		t.Errorf("Map snake was not read properly: %s facing %s", s.Head(), s.Facing())
+	}
+}
+
+// Test that a written game reads back the same
+func Test_WriteLevel(t *testing.T) {
+	g, err := game.ReadLevel(strings.NewReader(testLevel))
+	if err != nil {
+		t.Fatalf("Error reading level: %s", err)
+	}
+	for i := 0; i < 3; i++ {
+		g.Tick() // move the snakes so that they aren't where they started
+	}
+
+	var buf bytes.Buffer
+	if err := game.WriteLevel(&buf, &g); err != nil {
+		t.Fatalf("Error writing level: %s", err)
+	}
+	t.Logf("Written level:\n%s", buf.String())
+
+	rg, err := game.ReadLevel(&buf)
+	if err != nil {
+		t.Fatalf("Error reading written level: %s", err)
+	}
+	for _, id := range g.Snakes() {
+		s, _ := g.Snake(id)
+		rs, _ := rg.Snake(id)
+		if s.Head().String() != rs.Head().String() || s.Facing() != rs.Facing() {
+			t.Errorf("Snake %d did not read back the same: %s / %s", id, s.Head(), rs.Head())
+		}
+	}
+	if len(rg.Obstacles()) != len(g.Obstacles()) || rg.Topology() != g.Topology() {
+		t.Errorf("Level did not read back the same walls and topology")
+	}
+	// the level starts again on tick 0, so the food expires as many ticks from now
+	fs := g.Foods()
+	for i, f := range fs {
+		if f.Expires > 0 {
+			fs[i].Expires -= g.Ticks()
+		}
+	}
+	if fmt.Sprint(rg.Foods()) != fmt.Sprint(fs) {
+		t.Errorf("Level did not read back the same food: %v / %v", rg.Foods(), fs)
+	}
+}
+
+// Test that bad levels say where the problem is
+func Test_ReadLevelErrors(t *testing.T) {
+	bad := []struct {
+		level        string
+		line, column int
+	}{
+		{"size 2 1\nmap\n.S.\n..X\n", 4, 3},
+		{"size 2 1\nfood 1,7\nmap\n.S.\n...\n", 2, 6},
+		{"size 2 1\nsnake sideways 1,1\n", 2, 7},
+		{"size 2 1\nfood 1,1 poison\n", 2, 10},
+		{"size 2 1\nmap\n.S.\n", 3, 0},
+		{"size 2 1\nmap\nFS.\n...\n.F.\n", 5, 1},
+		{"size 2 1\nwall 1,1\nmap\n.S.\n#F.\n", 4, 2},
+		{"colour blue\n", 1, 1},
+		{"size 5 5\nsnake up 1,1 4,4 0,5\n", 2, 14},
+		{"size 5 5\nsnake up 1,1 1,2\n", 2, 7},
+	}
+
+	for _, b := range bad {
+		_, err := game.ReadLevel(strings.NewReader(b.level))
+		if le, ok := err.(game.LevelError); !ok {
+			t.Errorf("Bad level did not produce a level error: %v\n%s", err, b.level)
+		} else if le.Line != b.line || le.Column != b.column {
+			t.Errorf("Level error had the wrong position, wanted %d:%d : %s", b.line, b.column, le)
+		}
+	}
+}
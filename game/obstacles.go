@@ -0,0 +1,49 @@
+package game
+
+import "sort"
+
+/**
+ * Obstacles are blocked cells inside of the Grid, like the interior walls of a
+ * level.  A snake that moves onto an obstacle collides with it, and no snake or
+ * food may be placed on one.
+ *
+ * The Obstacles are a set of Points, so that checking a point is cheap no matter
+ * how big the walls are.
+ */
+
+// Obstacles a set of blocked Points
+type Obstacles map[Point]bool
+
+// NewObstacles from some blocked Points
+func NewObstacles(ps ...Point) Obstacles {
+	o := Obstacles{}
+	for _, p := range ps {
+		o.Add(p)
+	}
+	return o
+}
+
+// Add a blocked Point
+func (o Obstacles) Add(p Point) {
+	o[p] = true
+}
+
+// Is a point blocked
+func (o Obstacles) Contains(p Point) bool {
+	return o[p]
+}
+
+// Points of the obstacles as a slice, ordered by row then column
+func (o Obstacles) Points() []Point {
+	ps := []Point{}
+	for p := range o {
+		ps = append(ps, p)
+	}
+	sort.Slice(ps, func(i, j int) bool {
+		if ps[i].Y != ps[j].Y {
+			return ps[i].Y < ps[j].Y
+		}
+		return ps[i].X < ps[j].X
+	})
+	return ps
+}
@@ -0,0 +1,30 @@
+package game_test
+
+import (
+	"github.com/james-nesbitt/snake/game"
+	"testing"
+)
+
+// Test that obstacles remember what is blocked
+func Test_ObstaclesBasic(t *testing.T) {
+	o := game.NewObstacles(game.Point{X: 3, Y: 2}, game.Point{X: 1, Y: 2})
+	o.Add(game.Point{X: 5, Y: 0})
+
+	if !o.Contains(game.Point{X: 3, Y: 2}) || !o.Contains(game.Point{X: 5, Y: 0}) {
+		t.Errorf("Obstacles did not contain a blocked point")
+	}
+	if o.Contains(game.Point{X: 2, Y: 3}) {
+		t.Errorf("Obstacles contained a point that was not blocked")
+	}
+
+	ps := o.Points()
+	ex := []game.Point{{X: 5, Y: 0}, {X: 1, Y: 2}, {X: 3, Y: 2}}
+	if len(ps) != len(ex) {
+		t.Fatalf("Obstacles had the wrong number of points: %v", ps)
+	}
+	for i, p := range ex {
+		if !p.Equals(ps[i]) {
+			t.Errorf("Obstacle points were not in row order: %v", ps)
+		}
+	}
+}
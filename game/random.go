@@ -0,0 +1,66 @@
+package game
+
+import "time"
+
+/**
+ * Games need random numbers (for placing food) but a game also needs to be
+ * reproducible: the same seed and the same inputs must always give the same game,
+ * even after the game has been saved and restored.
+ *
+ * The math/rand sources can't be saved, so the game has its own small generator
+ * (splitmix64) whose whole state is a single number, which goes into snapshots.
+ * It implements rand.Source64, so it can be used with rand.New when needed.
+ *
+ * A new game is seeded from the clock (so that every game is different), and
+ * keeps its seed, so that it can be stored with the results and played again.
+ */
+
+// NewSeed a fresh seed, from the clock
+func NewSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// NewRandom random number generator from a seed
+func NewRandom(seed int64) *Random {
+	r := &Random{}
+	r.Seed(seed)
+	return r
+}
+
+// Random a seedable random number generator with a saveable state
+type Random struct {
+	state uint64
+}
+
+// Seed restart the generator from a seed
+func (r *Random) Seed(seed int64) {
+	r.state = uint64(seed)
+}
+
+// Uint64 next random number
+func (r *Random) Uint64() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Int63 next random number as a non-negative int64
+func (r *Random) Int63() int64 {
+	return int64(r.Uint64() >> 1)
+}
+
+// Intn random number in [0,n), which panics if n <= 0 like math/rand does
+func (r *Random) Intn(n int) int {
+	if n <= 0 {
+		panic("invalid argument to Intn")
+	}
+	// reject the values that would make the modulo biased
+	max := ^uint64(0) - ^uint64(0)%uint64(n)
+	for {
+		if v := r.Uint64(); v < max {
+			return int(v % uint64(n))
+		}
+	}
+}
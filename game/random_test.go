@@ -0,0 +1,98 @@
+package game_test
+
+import (
+	"github.com/james-nesbitt/snake/game"
+	"math/rand"
+	"testing"
+)
+
+// Test that the same seed gives the same numbers
+func Test_RandomSeed(t *testing.T) {
+	a, b, c := game.NewRandom(42), game.NewRandom(42), game.NewRandom(43)
+
+	same := true
+	for i := 0; i < 20; i++ {
+		av, bv, cv := a.Intn(100), b.Intn(100), c.Intn(100)
+		if av != bv {
+			t.Errorf("Randoms with the same seed produced different numbers: %d / %d", av, bv)
+		}
+		if av < 0 || av >= 100 {
+			t.Errorf("Random Intn produced a number out of range: %d", av)
+		}
+		same = same && av == cv
+	}
+	if same {
+		t.Errorf("Randoms with different seeds produced the same numbers")
+	}
+
+	// reseeding starts over
+	a.Seed(42)
+	b.Seed(42)
+	if a.Uint64() != b.Uint64() {
+		t.Errorf("Reseeded randoms produced different numbers")
+	}
+}
+
+// Test that the Random works as a math/rand source
+func Test_RandomSource(t *testing.T) {
+	var _ rand.Source64 = &game.Random{}
+
+	r := rand.New(game.NewRandom(7))
+	if n := r.Intn(10); n < 0 || n >= 10 {
+		t.Errorf("Random as a source produced a number out of range: %d", n)
+	}
+}
+
+// Test that new games are seeded differently, and keep their seed
+func Test_GameRandomNewSeed(t *testing.T) {
+	a, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 1, Y: 1})
+	b, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 1, Y: 1})
+	if a.RandomSeed() == 0 || a.RandomSeed() == b.RandomSeed() {
+		t.Errorf("New games were not seeded differently: %d / %d", a.RandomSeed(), b.RandomSeed())
+	}
+
+	c := game.Game{}
+	c.Seed(a.RandomSeed())
+	for i := 0; i < 10; i++ {
+		if an, cn := a.Random().Intn(1000), c.Random().Intn(1000); an != cn {
+			t.Errorf("Game seeded from another game's seed produced a different number: %d / %d", cn, an)
+		}
+	}
+}
+
+// Test that the game random state survives a snapshot
+func Test_GameRandomSnapshot(t *testing.T) {
+	g := snapshotGame(t)
+	g.Seed(99)
+	g.Random().Intn(10) // move the state on from the seed
+
+	b, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error marshalling game: %s", err)
+	}
+	rg := game.Game{}
+	if err := rg.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Error unmarshalling game: %s", err)
+	}
+	j, err := g.MarshalJSON()
+	if err != nil {
+		t.Fatalf("Error marshalling game: %s", err)
+	}
+	jg := game.Game{}
+	if err := jg.UnmarshalJSON(j); err != nil {
+		t.Fatalf("Error unmarshalling game: %s", err)
+	}
+	if rg.RandomSeed() != 99 || jg.RandomSeed() != 99 {
+		t.Errorf("Restored games lost their seed: %d / %d", rg.RandomSeed(), jg.RandomSeed())
+	}
+
+	for i := 0; i < 10; i++ {
+		n := g.Random().Intn(1000)
+		if rn := rg.Random().Intn(1000); n != rn {
+			t.Errorf("Binary restored game produced a different random number: %d / %d", rn, n)
+		}
+		if jn := jg.Random().Intn(1000); n != jn {
+			t.Errorf("JSON restored game produced a different random number: %d / %d", jn, n)
+		}
+	}
+}
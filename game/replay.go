@@ -0,0 +1,145 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/**
+ * A replay is the starting state of a game, and every input and event that
+ * happened to it after that, in order.  The game is deterministic, so playing
+ * the inputs back through a restored game reproduces the game exactly, which is
+ * how we can file and reproduce bug reports, and share notable games.
+ *
+ * A Recorder builds the replay while a game is played: it has to be told about
+ * every turn, food placement, speed change and tick as they are applied to the
+ * game.
+ *
+ * Replays are JSON friendly, with the start state held as a JSON game snapshot.
+ */
+
+// ReplayAction what happened in a replay event
+type ReplayAction string
+
+const (
+	ReplayTurn  ReplayAction = "turn"  // a snake turned
+	ReplayFood  ReplayAction = "food"  // food was placed
+	ReplayTick  ReplayAction = "tick"  // the game ticked
+	ReplaySpeed ReplayAction = "speed" // the game speed level was changed
+)
+
+// ReplayEvent a single input or event in a replay
+type ReplayEvent struct {
+	Seq     uint         `json:"seq"`               // order of the event, counting from 1
+	Action  ReplayAction `json:"action"`            // what happened
+	Snake   SnakeID      `json:"snake,omitempty"`   // the snake that turned
+	Dir     Vector       `json:"dir"`               // the turn direction
+	Food    Food         `json:"food"`              // the food that was placed
+	Speed   int          `json:"speed,omitempty"`   // the new speed level
+	Results TickResults  `json:"results,omitempty"` // the results of a tick
+}
+
+// Replay a game start state and all of the events after it
+type Replay struct {
+	Start  json.RawMessage `json:"start"`
+	Events []ReplayEvent   `json:"events"`
+}
+
+// NewRecorder start recording a game, from its current state
+func NewRecorder(g *Game) (*Recorder, error) {
+	st, err := json.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{replay: Replay{Start: st, Events: []ReplayEvent{}}}, nil
+}
+
+// Recorder builds a Replay as a game is played
+type Recorder struct {
+	replay Replay
+}
+
+// Turn record a snake turn
+func (r *Recorder) Turn(id SnakeID, d Vector) {
+	r.add(ReplayEvent{Action: ReplayTurn, Snake: id, Dir: d})
+}
+
+// Food record a food placement, which was added to the game with AddFood
+func (r *Recorder) Food(f Food) {
+	r.add(ReplayEvent{Action: ReplayFood, Food: f})
+}
+
+// Speed record a change to the game speed level, made with SetSpeed
+func (r *Recorder) Speed(n int) {
+	r.add(ReplayEvent{Action: ReplaySpeed, Speed: n})
+}
+
+// Tick record a game tick and its results
+func (r *Recorder) Tick(rs TickResults) {
+	r.add(ReplayEvent{Action: ReplayTick, Results: rs})
+}
+
+// add an event in sequence
+func (r *Recorder) add(e ReplayEvent) {
+	e.Seq = uint(len(r.replay.Events) + 1)
+	r.replay.Events = append(r.replay.Events, e)
+}
+
+// Replay of everything recorded so far
+func (r *Recorder) Replay() Replay {
+	return Replay{Start: r.replay.Start, Events: append([]ReplayEvent{}, r.replay.Events...)}
+}
+
+// ReplayMismatch a replayed tick that didn't produce the recorded results
+type ReplayMismatch struct {
+	Seq      uint
+	Recorded TickResults
+	Played   TickResults
+}
+
+// Error as a string
+func (e ReplayMismatch) Error() string {
+	return fmt.Sprintf("Replay event %d ticked %+v but %+v was recorded", e.Seq, e.Played, e.Recorded)
+}
+
+// Game at the start of the replay
+func (rp Replay) Game() (Game, error) {
+	g := Game{}
+	err := json.Unmarshal(rp.Start, &g)
+	return g, err
+}
+
+// Play the replay through its start game, checking that every tick produces the
+// recorded results.  The game is returned as it ended, along with the results of
+// every tick.  A tick that does not match gives a ReplayMismatch error.
+func (rp Replay) Play() (Game, []TickResults, error) {
+	g, err := rp.Game()
+	if err != nil {
+		return g, nil, err
+	}
+
+	trs := []TickResults{}
+	for _, e := range rp.Events {
+		switch e.Action {
+		case ReplayTurn:
+			if err := g.TurnSnake(e.Snake, e.Dir); err != nil {
+				return g, trs, err
+			}
+		case ReplayFood:
+			if err := g.AddFood(e.Food); err != nil {
+				return g, trs, err
+			}
+		case ReplaySpeed:
+			g.SetSpeed(e.Speed)
+		case ReplayTick:
+			rs, _ := g.Tick() // collisions are in the results
+			trs = append(trs, rs)
+			if !rs.Equals(e.Results) {
+				return g, trs, ReplayMismatch{Seq: e.Seq, Recorded: e.Results, Played: rs}
+			}
+		default:
+			return g, trs, fmt.Errorf("Replay event %d has an unknown action %q", e.Seq, e.Action)
+		}
+	}
+	return g, trs, nil
+}
@@ -0,0 +1,112 @@
+package game_test
+
+import (
+	"encoding/json"
+	"github.com/james-nesbitt/snake/game"
+	"testing"
+)
+
+// play a recorded game, with turns, food and a collision at the end
+func recordGame(t *testing.T) (*game.Game, *game.Recorder, []game.TickResults) {
+	tg := testingGame(t)
+	r, err := game.NewRecorder(tg.game)
+	if err != nil {
+		t.Fatalf("Error creating recorder: %s", err)
+	}
+
+	trs := []game.TickResults{}
+	tick := func(n int) {
+		for i := 0; i < n; i++ {
+			rs, _ := tg.game.Tick()
+			r.Tick(rs)
+			trs = append(trs, rs)
+		}
+	}
+	turn := func(d game.Vector) {
+		tg.game.Turn(d)
+		r.Turn(0, d)
+	}
+	food := func(p game.Point) {
+		tg.game.AddFood(game.NewFood(p))
+		r.Food(game.NewFood(p))
+	}
+
+	tick(3) // (5,5) -> (5,8)
+	turn(game.Left)
+	tick(2) // (5,8) -> (3,8)
+	turn(game.Down)
+	tick(4) // (3,8) -> (3,4) ate
+	food(game.Point{X: 3, Y: 3})
+	tick(1) // (3,4) -> (3,3) ate
+	food(game.Point{X: 9, Y: 9})
+	turn(game.Right)
+	tick(1) // (3,3) -> (4,3)
+	turn(game.Up)
+	tick(1) // (4,3) -> (4,4)
+	turn(game.Left)
+	tick(5) // (4,4) -> (0,4) and a boundary collision
+
+	return tg.game, r, trs
+}
+
+// Test that a replay plays back the same game
+func Test_ReplayPlay(t *testing.T) {
+	g, r, trs := recordGame(t)
+
+	// Replays should survive being sent around as JSON
+	b, err := json.Marshal(r.Replay())
+	if err != nil {
+		t.Fatalf("Error marshalling replay: %s", err)
+	}
+	rp := game.Replay{}
+	if err := json.Unmarshal(b, &rp); err != nil {
+		t.Fatalf("Error unmarshalling replay: %s", err)
+	}
+
+	if len(rp.Events) != 24 {
+		t.Errorf("Replay has the wrong number of events: %d", len(rp.Events))
+	}
+	for i, e := range rp.Events {
+		if e.Seq != uint(i+1) {
+			t.Errorf("Replay event is out of sequence: %d at %d", e.Seq, i)
+		}
+	}
+
+	pg, ptrs, err := rp.Play()
+	if err != nil {
+		t.Fatalf("Error playing replay: %s", err)
+	}
+	if len(ptrs) != len(trs) {
+		t.Fatalf("Replay had the wrong number of ticks: %d / %d", len(ptrs), len(trs))
+	}
+	for i := range trs {
+		if !trs[i].Equals(ptrs[i]) {
+			t.Errorf("Replay tick %d was different: %+v / %+v", i, ptrs[i], trs[i])
+		}
+	}
+	if pg.Head().String() != g.Head().String() || pg.Ticks() != g.Ticks() || pg.Alive(0) {
+		t.Errorf("Replay did not end in the same game: %s / %s", pg.Head(), g.Head())
+	}
+}
+
+// Test that a replay that doesn't match the game is caught
+func Test_ReplayMismatch(t *testing.T) {
+	_, r, _ := recordGame(t)
+	rp := r.Replay()
+
+	// drop the last turn, so that the snake doesn't run along the wall (a near-miss
+	// on the tick before the end) and collide at the end
+	es := []game.ReplayEvent{}
+	for _, e := range rp.Events {
+		if e.Seq != 19 {
+			es = append(es, e)
+		}
+	}
+	rp.Events = es
+
+	if _, _, err := rp.Play(); err == nil {
+		t.Errorf("Replay with a missing turn did not produce an error")
+	} else if m, ok := err.(game.ReplayMismatch); !ok || m.Seq != 23 {
+		t.Errorf("Replay with a missing turn did not produce a mismatch on the near-miss tick: %s", err)
+	}
+}
@@ -0,0 +1,88 @@
+package game
+
+/**
+ * A game ends for each snake when it collides with something, or for everyone
+ * when the snakes fill the board, which is a win.  The game keeps score of how
+ * every snake did, so that a final GameResult can be given for each of them.
+ */
+
+// EndReason how a snake's game ended
+type EndReason uint8
+
+const (
+	Playing     EndReason = iota // the snake is still playing
+	Victory                      // the snakes filled the board
+	HitBoundary                  // the snake collided with the grid boundary
+	HitObstacle                  // the snake collided with an obstacle
+	HitSnake                     // the snake collided with a snake body
+	HitHead                      // the snake collided head first with another snake
+)
+
+// Convert to a printable string
+func (r EndReason) String() string {
+	switch r {
+	case Playing:
+		return "playing"
+	case Victory:
+		return "victory"
+	case HitBoundary:
+		return "boundary collision"
+	case HitObstacle:
+		return "obstacle collision"
+	case HitSnake:
+		return "snake collision"
+	case HitHead:
+		return "head collision"
+	default:
+		return "unknown"
+	}
+}
+
+// GameResult how a snake did in a game
+type GameResult struct {
+	Snake     SnakeID   `json:"snake"`
+	Reason    EndReason `json:"reason"`    // how the game ended, if it has
+	Length    uint      `json:"length"`    // how long the snake is
+	Ticks     uint      `json:"ticks"`     // how many ticks the snake survived
+	FoodEaten uint      `json:"foodEaten"` // how much food the snake ate
+	Score     int       `json:"score"`     // the points that the snake scored
+}
+
+// the running record of how a snake is doing, kept by the game
+type snakeRecord struct {
+	end   EndReason
+	ticks uint
+	eaten uint
+	grow  uint     // how many more ticks the snake grows on, from food it ate
+	score Score    // the snake score, and how it was made
+	turns []Vector // queued turns, if the turn policy buffers them
+}
+
+// use up some pending growth to shrink by, returning what is left to shrink
+func (r *snakeRecord) shrinkGrowth(n uint) uint {
+	if n <= r.grow {
+		r.grow -= n
+		return 0
+	}
+	n -= r.grow
+	r.grow = 0
+	return n
+}
+
+// the reason a tick result ended a snake's game
+func (r TickResult) endReason() EndReason {
+	switch {
+	case r.Victory:
+		return Victory
+	case r.BoundaryCollision:
+		return HitBoundary
+	case r.ObstacleCollision:
+		return HitObstacle
+	case r.HeadCollision:
+		return HitHead
+	case r.SnakeCollision:
+		return HitSnake
+	default:
+		return Playing
+	}
+}
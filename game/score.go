@@ -0,0 +1,154 @@
+package game
+
+import "fmt"
+
+/**
+ * The game keeps the score for every snake, so that UIs and leaderboards all
+ * read the same number.  The score is changed on every Tick, following the game
+ * ScoreRules:
+ *  1. eating food scores the points for its kind, and bonus food also scores its
+ *     Value
+ *  2. eating food within StreakTicks of the last food continues a streak, which
+ *     scores a Streak bonus for every food in the streak before it
+ *  3. eating food while the game speed is up scores a Speed bonus for every speed
+ *     level
+ *  4. a near-miss, where a snake head ends a tick right next to something that it
+ *     could have collided with (other than its own neck), loses NearMiss points.
+ *     Only moving into a near-miss counts, so a snake that runs along a wall
+ *     loses the points once, and again only after it has moved away
+ *  5. every tick survived scores Tick points
+ *
+ * The rules are data, like the TurnPolicy, so that they are saved with the game
+ * and a restored game scores the same way.
+ */
+
+// ScoreRules how the game scores
+type ScoreRules struct {
+	Food        map[FoodKind]int `json:"food,omitempty"` // points for eating each kind of food
+	Streak      int              `json:"streak"`         // bonus points for each earlier food in a streak
+	StreakTicks uint             `json:"streakTicks"`    // how many ticks after eating that the next food continues a streak
+	Speed       int              `json:"speed"`          // bonus points for each speed level, when eating food
+	NearMiss    int              `json:"nearMiss"`       // points lost for each near-miss
+	Tick        int              `json:"tick"`           // points for each tick survived
+}
+
+// DefaultScoreRules the rules that a new game scores with
+func DefaultScoreRules() ScoreRules {
+	return ScoreRules{
+		Food: map[FoodKind]int{
+			NormalFood: 10,
+			BonusFood:  10,
+			GrowFood:   10,
+			ShrinkFood: 5,
+			SpeedFood:  5,
+		},
+		Streak:      5,
+		StreakTicks: 20,
+		Speed:       2,
+		NearMiss:    1,
+	}
+}
+
+// Score how many points a snake has, and where they came from
+type Score struct {
+	Points     int  `json:"points"`     // the score
+	Food       int  `json:"food"`       // points from eating food
+	Bonus      int  `json:"bonus"`      // points from streaks, speed and survival
+	Penalty    int  `json:"penalty"`    // points lost to near-misses
+	Streak     uint `json:"streak"`     // how many foods are in the current streak
+	NearMisses uint `json:"nearMisses"` // how many near-misses there have been
+	LastFood   uint `json:"lastFood"`   // the tick that the snake last ate on
+	Near       bool `json:"near"`       // the snake head is in a near-miss, which isn't counted again until it gets away
+}
+
+// add some points to the score
+func (s *Score) add(food, bonus, penalty int) int {
+	s.Food += food
+	s.Bonus += bonus
+	s.Penalty += penalty
+	n := food + bonus - penalty
+	s.Points += n
+	return n
+}
+
+// score a tick for a snake that survived it, returning the points scored
+func (sr ScoreRules) score(s *Score, r TickResult, tick uint, speed int) int {
+	food, bonus, penalty := 0, sr.Tick, 0
+	if r.AteFood {
+		food = sr.Food[r.Food.Kind]
+		if r.Food.Kind == BonusFood {
+			food += r.Food.Value
+		}
+		if s.Streak > 0 && tick-s.LastFood <= sr.StreakTicks {
+			bonus += sr.Streak * int(s.Streak)
+			s.Streak++
+		} else {
+			s.Streak = 1
+		}
+		s.LastFood = tick
+		if speed > 0 {
+			bonus += sr.Speed * speed
+		}
+	}
+	if r.NearMiss && !s.Near {
+		penalty = sr.NearMiss
+		s.NearMisses++
+	}
+	s.Near = r.NearMiss
+	return s.add(food, bonus, penalty)
+}
+
+// find if a snake head has had a near-miss, with something blocking a point next
+// to it (other than its own neck)
+func (g *Game) nearMiss(id SnakeID) bool {
+	s := &g.snakes[id]
+	hp := s.HeadPoint()
+	var neck Point
+	if s.Length() > 1 {
+		neck = s.Head().Next().Point()
+	}
+	live := g.Living()
+	for _, d := range []Vector{Up, Down, Left, Right} {
+		p := g.topology.Normalize(g.grid, hp.Move(d))
+		if s.Length() > 1 && p.Equals(neck) {
+			continue
+		}
+		if !g.grid.Contains(p) || g.walls.Contains(p) {
+			return true
+		}
+		for _, o := range live {
+			if g.snakes[o].Contains(p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetScoreRules change how the game scores from now on
+func (g *Game) SetScoreRules(sr ScoreRules) {
+	g.scoring = sr
+}
+
+// ScoreRules how the game scores
+func (g *Game) ScoreRules() ScoreRules {
+	return g.scoring
+}
+
+// Score for a snake
+func (g *Game) Score(id SnakeID) (Score, error) {
+	if !g.valid(id) {
+		return Score{}, fmt.Errorf("Game has no snake %d", id)
+	}
+	return g.records[id].score, nil
+}
+
+// Speed level of the game, which speed food changes
+func (g *Game) Speed() int {
+	return g.speed
+}
+
+// SetSpeed change the speed level of the game
+func (g *Game) SetSpeed(n int) {
+	g.speed = n
+}
@@ -0,0 +1,124 @@
+package game_test
+
+import (
+	"github.com/james-nesbitt/snake/game"
+	"testing"
+)
+
+// Test scoring food with the default rules, with a streak
+func Test_ScoreFood(t *testing.T) {
+	g := foodGame(t, game.NewFood(game.Point{X: 3, Y: 5}), game.Food{Point: game.Point{X: 5, Y: 5}, Kind: game.BonusFood, Value: 7})
+
+	if r := foodTick(t, &g); r.Points != 10 {
+		t.Errorf("Eating normal food scored %d", r.Points)
+	}
+	if r := foodTick(t, &g); r.Points != 0 {
+		t.Errorf("Moving scored %d", r.Points)
+	}
+	if r := foodTick(t, &g); r.Points != 10+7+5 { // food, its value and a streak of 1
+		t.Errorf("Eating bonus food in a streak scored %d", r.Points)
+	}
+
+	sc, err := g.Score(0)
+	if err != nil {
+		t.Fatalf("Could not get the snake score: %s", err)
+	}
+	if sc.Points != 32 || sc.Food != 27 || sc.Bonus != 5 || sc.Streak != 2 || sc.LastFood != 3 {
+		t.Errorf("Snake has the wrong score: %+v", sc)
+	}
+	if r, _ := g.Result(0); r.Score != 32 {
+		t.Errorf("Snake result has the wrong score: %+v", r)
+	}
+	if _, err := g.Score(1); err == nil {
+		t.Errorf("Game gave a score for a snake that it doesn't have")
+	}
+}
+
+// Test that a streak is broken by taking too long to eat
+func Test_ScoreStreak(t *testing.T) {
+	g := foodGame(t, game.NewFood(game.Point{X: 3, Y: 5}), game.NewFood(game.Point{X: 5, Y: 5}), game.NewFood(game.Point{X: 9, Y: 5}))
+	sr := g.ScoreRules()
+	sr.StreakTicks = 3
+	g.SetScoreRules(sr)
+
+	foodTick(t, &g) // (3,5) on tick 1
+	foodTick(t, &g)
+	if r := foodTick(t, &g); r.Points != 15 { // (5,5) on tick 3
+		t.Errorf("Eating food in a streak scored %d", r.Points)
+	}
+	for i := 0; i < 3; i++ {
+		foodTick(t, &g)
+	}
+	if r := foodTick(t, &g); r.Points != 10-1 { // (9,5) on tick 7, a near-miss with the boundary
+		t.Errorf("Eating food after a streak scored %d", r.Points)
+	}
+}
+
+// Test the speed bonus, and that speed food changes the game speed
+func Test_ScoreSpeed(t *testing.T) {
+	g := foodGame(t, game.Food{Point: game.Point{X: 3, Y: 5}, Kind: game.SpeedFood, Value: 2})
+
+	if r := foodTick(t, &g); r.Points != 5+2*2 {
+		t.Errorf("Eating speed food scored %d", r.Points)
+	}
+	if g.Speed() != 2 {
+		t.Errorf("Speed food didn't change the game speed: %d", g.Speed())
+	}
+}
+
+// Test near-miss penalties, and custom rules
+func Test_ScoreNearMiss(t *testing.T) {
+	g := foodGame(t)
+	g.SetScoreRules(game.ScoreRules{NearMiss: 3, Tick: 1})
+	if err := g.SetObstacles(game.NewObstacles(game.Point{X: 4, Y: 6})); err != nil {
+		t.Fatalf("Game rejected a valid obstacle: %s", err)
+	}
+
+	if r := foodTick(t, &g); r.NearMiss || r.Points != 1 { // (3,5)
+		t.Errorf("Snake had an unexpected near-miss: %+v", r)
+	}
+	if r := foodTick(t, &g); !r.NearMiss || r.Points != -2 { // (4,5), under the obstacle
+		t.Errorf("Snake didn't have a near-miss: %+v", r)
+	}
+	if sc, _ := g.Score(0); sc.Points != -1 || sc.Penalty != 3 || sc.NearMisses != 1 {
+		t.Errorf("Snake has the wrong score: %+v", sc)
+	}
+}
+
+// Test that running along a wall is only one near-miss, even in a restored game
+func Test_ScoreNearMissWall(t *testing.T) {
+	g := foodGame(t)
+	g.SetScoreRules(game.ScoreRules{NearMiss: 3, Tick: 1})
+	if err := g.SetObstacles(game.NewObstacles(game.Point{X: 4, Y: 6}, game.Point{X: 5, Y: 6}, game.Point{X: 6, Y: 6})); err != nil {
+		t.Fatalf("Game rejected valid obstacles: %s", err)
+	}
+
+	foodTick(t, &g)                                          // (3,5)
+	if r := foodTick(t, &g); !r.NearMiss || r.Points != -2 { // (4,5), along the wall
+		t.Errorf("Snake didn't have a near-miss: %+v", r)
+	}
+	b, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Could not save the game: %s", err)
+	}
+	var rg game.Game
+	if err := rg.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Could not restore the game: %s", err)
+	}
+	for _, x := range []int{5, 6} {
+		if r := foodTick(t, &rg); !r.NearMiss || r.Points != 1 {
+			t.Errorf("Snake lost points again along the wall at (%d,5): %+v", x, r)
+		}
+	}
+	for _, x := range []int{7, 8} {
+		if r := foodTick(t, &rg); r.NearMiss || r.Points != 1 { // away from the wall
+			t.Errorf("Snake had an unexpected near-miss at (%d,5): %+v", x, r)
+		}
+	}
+	if r := foodTick(t, &rg); !r.NearMiss || r.Points != -2 { // (9,5), by the boundary
+		t.Errorf("Snake didn't have a new near-miss: %+v", r)
+	}
+	if sc, _ := rg.Score(0); sc.Points != 1 || sc.Penalty != 6 || sc.NearMisses != 2 {
+		t.Errorf("Snake has the wrong score: %+v", sc)
+	}
+}
@@ -1,107 +1,166 @@
 package game
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 /**
- * Aspects of the snake as a linked list of points are represented Here
+ * Aspects of the snake as a list of points are represented Here
  *
- * A Snake is composed of Segments, which are linked list nodes of points
- * The behaviour is pretty standard Linked List but focused on functionality
- * related to what we need for the snake game.
+ * A Snake is a ring buffer of points, with the head at the front and the tail at
+ * the back, and an occupancy index of the points that it covers.  Every snake
+ * operation needed on a Tick (advance, grow, length and contains) is O(1), no
+ * matter how long the snake gets.
  *
  * These are:
  *  1. A snake is a combination of a head and a direction
- *  2. Segments of the snake are nodes of a linked List
+ *  2. Segments of the snake are positions in the ring, counting from the head,
+ *     which can be walked like a linked list with Head() and Next()
  *  3. The snake only moves forward, so the Head of the snake is the most important Segment
  *  4. When a snake moves, we set a new Head segment and cut off the last segment
- * 	5. A snake doesn't detect it's own Cycles (the Game does all of the validation)
+ *  5. A snake doesn't detect it's own Cycles (the Game does all of the validation)
  *     The snake does have capability to discover if it contains a Point
  *  6. A snake either moves forward, or grows forward
  *  7. A grow means to add a new head which increases length
  *  8. A move means to add a new head, but to drop the last segment
  */
 
-// Segment as a linked list element of a snake
+// Segment of a snake, as its position counting from the head (0)
+// @NOTE a segment is a position in the snake, not a point, so a segment kept
+//       from before the snake moved now refers to the point at that position.
 type Segment struct {
-	next  *Segment
-	point Point
+	snake *Snake
+	index uint
 }
 
 // Point for the segment indicating its position
 func (s *Segment) Point() Point {
-	return s.point
+	return s.snake.at(s.index)
 }
 
-// Next segment in the linked list
+// Next segment towards the tail, or nil for the tail
 func (s *Segment) Next() *Segment {
-	return s.next
+	if s.index+1 >= s.snake.length {
+		return nil
+	}
+	return &Segment{snake: s.snake, index: s.index + 1}
 }
 
-// Length of the linked list
+// Length of the snake from this segment to the tail
 func (s *Segment) Length() uint {
-	if s.Next() == nil {
-		return 1
-	} else {
-		return 1 + s.Next().Length()
-	}
+	return s.snake.length - s.index
 }
 
-// Remove the last segment in the list by unreferencing the second last .next
+// Remove the last segment of the snake, as long as it is after this segment
 func (s *Segment) Pop() error {
 	if s.Next() == nil {
 		return errors.New("List too short to cut off end")
 	}
-
-	if s.Next().Next() == nil {
-		s.next = nil
-	} else {
-		s.Next().Pop()
-	}
-
+	s.snake.pop()
 	return nil
 }
 
-// Detect Point in Linked List
+// Detect Point in the snake from this segment to the tail
 // @note We never need a full cycle test as we only ever need to test the head
 //       Point, as it is the only new point in the snake
 func (s *Segment) FindPoint(p Point) bool {
-	if s.point.Equals(p) {
-		return true
-	} else if s.next == nil {
-		return false
-	} else {
-		return s.next.FindPoint(p)
+	if s.index == 0 {
+		return s.snake.Contains(p) // the whole snake, so use the index
 	}
+	for i := s.index; i < s.snake.length; i++ {
+		if s.snake.at(i).Equals(p) {
+			return true
+		}
+	}
+	return false
 }
 
-// String convert the Segment for logging
+// String convert the Segment, and every segment after it, for logging
 func (s *Segment) String() string {
-	if s.Next() == nil {
-		return s.Point().String()
-	} else {
-		return s.Point().String() + "," + s.Next().String()
+	var b strings.Builder
+	for i := s.index; i < s.snake.length; i++ {
+		if i > s.index {
+			b.WriteByte(',')
+		}
+		b.WriteString(s.snake.at(i).String())
 	}
+	return b.String()
 }
 
 // New Snake from a head point and a Vector
 func NewSnake(p Point, d Vector) Snake {
-	return Snake{head: &Segment{point: p}, dir: d}
+	s := Snake{points: make([]Point, snakeStartCap), occupied: map[Point]uint{}, dir: d}
+	s.points[0] = p
+	s.length = 1
+	s.occupied[p] = 1
+	return s
+}
+
+// make a snake from its points, head first
+func snakeFromPoints(ps []Point, d Vector) Snake {
+	s := NewSnake(ps[len(ps)-1], d)
+	for i := len(ps) - 2; i >= 0; i-- {
+		s.GrowTo(ps[i])
+	}
+	return s
 }
 
-// Snake as a linked list of segments and a facing direction
+// Clone a copy of the snake, which doesn't share its ring or index with the
+// original, so that either can move without changing the other
+func (s *Snake) Clone() Snake {
+	return snakeFromPoints(s.Points(), s.dir)
+}
+
+// how many points a new snake has room for before its ring has to grow
+const snakeStartCap = 8
+
+// Snake as a ring buffer of points and a facing direction.
+// @NOTE a Snake must not be copied by value, as a copy shares its ring and index
+//       with the original, and moving either breaks the other: use Clone.
 type Snake struct {
-	head *Segment
-	dir  Vector
+	points   []Point        // the ring, which is only ever grown
+	head     uint           // index of the head point in the ring
+	length   uint           // how many points of the ring are in the snake
+	occupied map[Point]uint // how many times each point is in the snake
+	dir      Vector
+}
+
+// point at a position in the snake, counting from the head
+func (s *Snake) at(i uint) Point {
+	n := uint(len(s.points))
+	return s.points[(s.head+n-i)%n]
+}
+
+// remove the tail point
+func (s *Snake) pop() {
+	p := s.at(s.length - 1)
+	if s.occupied[p] <= 1 {
+		delete(s.occupied, p)
+	} else {
+		s.occupied[p]--
+	}
+	s.length--
+}
+
+// make the ring bigger, keeping the points in order with the tail at 0
+func (s *Snake) expand() {
+	ps := make([]Point, s.length, 2*s.length)
+	for i := uint(0); i < s.length; i++ {
+		ps[s.length-1-i] = s.at(i)
+	}
+	s.points = ps[:cap(ps)]
+	s.head = s.length - 1
 }
 
 // Get the Head Segment for the snake
 func (s *Snake) Head() *Segment {
-	return s.head
+	return &Segment{snake: s, index: 0}
 }
 
 // Get the Head Point for the snake
 func (s *Snake) HeadPoint() Point {
-	return s.Head().Point()
+	return s.at(0)
 }
 
 // Get the snake facing direction
@@ -110,9 +169,8 @@ func (s *Snake) Facing() Vector {
 }
 
 // How long is the Snake
-// @NOTE mainly used for testing
 func (s *Snake) Length() uint {
-	return s.Head().Length()
+	return s.length
 }
 
 // Change Direction of the snake, to any vector
@@ -124,38 +182,61 @@ func (s *Snake) Turn(d Vector) {
 
 // Grow the snake ahead one step in its direction by adding a new head segment
 func (s *Snake) Grow() {
-	h := s.Head()
-	hp := h.Point()
-
-	nhp := hp.Move(s.dir)
-	nh := Segment{next: h, point: nhp}
+	hp := s.HeadPoint()
+	s.GrowTo(hp.Move(s.dir))
+}
 
-	s.head = &nh
+// GrowTo grow the snake by adding a new head segment at a Point
+// @NOTE the game uses this to place the head where the grid topology puts it,
+//       as the snake has no grid awareness
+func (s *Snake) GrowTo(p Point) {
+	if s.length == uint(len(s.points)) {
+		s.expand()
+	}
+	s.head = (s.head + 1) % uint(len(s.points))
+	s.points[s.head] = p
+	s.length++
+	s.occupied[p]++
 }
 
 // Move the snake ahead one step in its direction by adding a new head segment
 // and removing the last element in the list
 func (s *Snake) Advance() {
 	s.Grow()
-	s.Head().Pop()
+	s.pop()
+}
+
+// AdvanceTo move the snake by adding a new head segment at a Point, and removing
+// the last element in the list
+func (s *Snake) AdvanceTo(p Point) {
+	s.GrowTo(p)
+	s.pop()
+}
+
+// Shrink the snake by removing up to n tail segments, but never its head, and
+// return how many were removed
+func (s *Snake) Shrink(n uint) uint {
+	if n >= s.length {
+		n = s.length - 1
+	}
+	for i := uint(0); i < n; i++ {
+		s.pop()
+	}
+	return n
 }
 
 // Detect if a Point is in the Snake
 func (s *Snake) Contains(p Point) bool {
-	return s.Head().FindPoint(p)
+	return s.occupied[p] > 0
 }
 
-// Points of the snake as a slice
+// Points of the snake as a slice, from head to tail
 // @NOTE I am not convinced that we should use this as opposed to relying on the
 //       the snake head point with recursive functionality
 func (s *Snake) Points() []Point {
-	ps := []Point{}
-
-	current := s.Head()
-	for current != nil {
-		ps = append(ps, current.Point())
-		current = current.Next()
+	ps := make([]Point, 0, s.length)
+	for i := uint(0); i < s.length; i++ {
+		ps = append(ps, s.at(i))
 	}
-
 	return ps
 }
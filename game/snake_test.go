@@ -127,6 +127,19 @@ func Test_snake_points(t *testing.T) {
 	}
 }
 
+// Test the snake segments as strings, from any segment to the tail
+func Test_snake_string(t *testing.T) {
+	s := game.NewSnake(game.Point{X: 1, Y: 1}, game.Up)
+	s.Grow()
+	s.Grow()
+	if str := s.Head().String(); str != "(1,3),(1,2),(1,1)" {
+		t.Errorf("Snake has the wrong string: %s", str)
+	}
+	if str := s.Head().Next().String(); str != "(1,2),(1,1)" {
+		t.Errorf("Snake segment has the wrong string: %s", str)
+	}
+}
+
 // Test that turning the snake moves as a Point.Move() (which we have a unit test for)
 func Test_snake_turn(t *testing.T) {
 	moves := []game.Vector{
@@ -160,3 +173,108 @@ func Test_snake_turn(t *testing.T) {
 		t.Errorf("Snake advance landed on the wrong point")
 	}
 }
+
+// Test that a snake keeps its points in order as its ring grows and wraps
+func Test_snake_ring(t *testing.T) {
+	s := starter_snake(game.Right)
+	for i := 0; i < 20; i++ {
+		s.Grow()
+	}
+	for i := 0; i < 35; i++ {
+		s.Advance()
+	}
+
+	if s.Length() != 21 {
+		t.Errorf("Snake reports incorrect length. Wanted 21, got %d", s.Length())
+	}
+
+	// the snake is a straight line, from its head back to its tail
+	hp := s.HeadPoint()
+	n := 0
+	for sg := s.Head(); sg != nil; sg = sg.Next() {
+		if !sg.Point().Equals(hp.Move(game.Vector{X: -n, Y: 0})) {
+			t.Errorf("Snake segment %d was on the wrong point: %s", n, sg.Point())
+		}
+		n++
+	}
+	if n != 21 {
+		t.Errorf("Walking the snake found the wrong number of segments: %d", n)
+	}
+
+	tp := hp.Move(game.Vector{X: -20, Y: 0})
+	if !s.Contains(tp) {
+		t.Errorf("Snake didn't detect that it contains its tail point")
+	}
+	if s.Contains(tp.Move(game.Left)) {
+		t.Errorf("Snake thinks it still contains a point that it moved off")
+	}
+}
+
+// Test that a cloned snake moves without changing the original
+func Test_snake_clone(t *testing.T) {
+	s := starter_snake(game.Right)
+	s.Grow()
+	c := s.Clone()
+	c.Advance()
+	c.Turn(game.Up)
+
+	if !s.Contains(s.HeadPoint()) || s.HeadPoint() != head.Move(game.Right) || s.Facing() != game.Right {
+		t.Errorf("Moving a clone changed the original snake: %v", s.Points())
+	}
+	if c.Contains(head) || c.Length() != 2 {
+		t.Errorf("Clone did not move: %v", c.Points())
+	}
+}
+
+// a long snake, snaking back and forth across rows of the given width
+func long_snake(length, width int) game.Snake {
+	s := game.NewSnake(game.Point{X: 0, Y: 0}, game.Right)
+	for s.Length() < uint(length) {
+		hp := s.HeadPoint()
+		switch {
+		case s.Facing() == game.Right && hp.X == width-1, s.Facing() == game.Left && hp.X == 0:
+			s.Turn(game.Up)
+		case s.Facing() == game.Up && hp.X == 0:
+			s.Turn(game.Right)
+		case s.Facing() == game.Up:
+			s.Turn(game.Left)
+		}
+		s.Grow()
+	}
+	return s
+}
+
+func BenchmarkSnakeAdvance(b *testing.B) {
+	s := long_snake(20000, 100)
+	s.Turn(game.Up)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Advance()
+	}
+}
+
+func BenchmarkSnakeGrow(b *testing.B) {
+	s := long_snake(10000, 100)
+	s.Turn(game.Up)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Grow()
+	}
+}
+
+func BenchmarkSnakeString(b *testing.B) {
+	s := long_snake(20000, 100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = s.Head().String()
+	}
+}
+
+func BenchmarkSnakeContains(b *testing.B) {
+	s := long_snake(20000, 100)
+	p := game.Point{X: 50, Y: 500} // beyond the tail of the snake
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Contains(p)
+	}
+}
@@ -0,0 +1,430 @@
+package game
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+/**
+ * Snapshots of the full game state, so that a game can be saved, resumed or
+ * sent across a network.
+ *
+ * There are two encodings:
+ *  1. JSON, using MarshalJSON/UnmarshalJSON on the Game, Snake and Segment
+ *  2. a compact binary encoding, using MarshalBinary/UnmarshalBinary on the Game
+ *
+ * Both hold everything that decides how the game plays on: the grid and its
+ * topology, the obstacles, every snake's segments in order (head first) and its
+ * facing, how every snake is doing (which are dead, their results so far and
+ * any growth still to come), every snake score, the score rules, the speed level,
+ * every item of food, the tick count, the game random seed and the state of its
+ * random numbers, the turn policy and any queued turns.  A restored game behaves
+ * the same as the original on every following Tick.
+ */
+
+// JSON form of a Game
+type gameJSON struct {
+	Grid     Vector       `json:"grid"`
+	Topology string       `json:"topology"`
+	Walls    []Point      `json:"walls,omitempty"`
+	Snakes   []Snake      `json:"snakes"`
+	Results  []GameResult `json:"results"`
+	Scores   []Score      `json:"scores"`
+	Queued   [][]Vector   `json:"queued,omitempty"`  // queued turns for each snake
+	Growing  []uint       `json:"growing,omitempty"` // growth still to come for each snake
+	Foods    []Food       `json:"foods"`
+	Ticks    uint         `json:"ticks"`
+	Random   uint64       `json:"random"`
+	Seed     int64        `json:"seed"`
+	Turns    TurnPolicy   `json:"turns"`
+	Scoring  ScoreRules   `json:"scoring"`
+	Speed    int          `json:"speed"`
+}
+
+// JSON form of a Snake
+type snakeJSON struct {
+	Facing   Vector   `json:"facing"`
+	Segments *Segment `json:"segments"`
+}
+
+// MarshalJSON the full game state
+func (g Game) MarshalJSON() ([]byte, error) {
+	gj := gameJSON{
+		Grid:     Vector(g.grid),
+		Topology: g.topology.String(),
+		Walls:    g.walls.Points(),
+		Snakes:   g.snakes,
+		Results:  g.Results(),
+		Foods:    g.Foods(),
+		Ticks:    g.ticks,
+		Random:   g.rng.state,
+		Seed:     g.seed,
+		Turns:    g.turns,
+		Scoring:  g.scoring,
+		Speed:    g.speed,
+	}
+	for i, r := range g.records {
+		gj.Scores = append(gj.Scores, r.score)
+		if len(r.turns) > 0 {
+			if gj.Queued == nil {
+				gj.Queued = make([][]Vector, len(g.records))
+			}
+			gj.Queued[i] = r.turns
+		}
+		if r.grow > 0 {
+			if gj.Growing == nil {
+				gj.Growing = make([]uint, len(g.records))
+			}
+			gj.Growing[i] = r.grow
+		}
+	}
+	return json.Marshal(gj)
+}
+
+// UnmarshalJSON a full game state, which is validated
+func (g *Game) UnmarshalJSON(b []byte) error {
+	gj := gameJSON{}
+	if err := json.Unmarshal(b, &gj); err != nil {
+		return err
+	}
+
+	t, ok := parseTopology(gj.Topology)
+	if !ok {
+		return fmt.Errorf("Unknown topology %q", gj.Topology)
+	}
+	ng := Game{
+		grid:     Grid(gj.Grid),
+		topology: t,
+		walls:    NewObstacles(gj.Walls...),
+		snakes:   gj.Snakes,
+		records:  make([]snakeRecord, len(gj.Snakes)),
+		foods:    gj.Foods,
+		ticks:    gj.Ticks,
+		rng:      Random{state: gj.Random},
+		seed:     gj.Seed,
+		turns:    gj.Turns,
+		scoring:  gj.Scoring,
+		speed:    gj.Speed,
+	}
+	for _, r := range gj.Results {
+		if !ng.valid(r.Snake) {
+			return fmt.Errorf("Game has no snake %d for a result", r.Snake)
+		}
+		ng.records[r.Snake] = snakeRecord{end: r.Reason, ticks: r.Ticks, eaten: r.FoodEaten}
+	}
+	for i, sc := range gj.Scores {
+		if !ng.valid(SnakeID(i)) {
+			return fmt.Errorf("Game has no snake %d for a score", i)
+		}
+		ng.records[i].score = sc
+	}
+	for i, ts := range gj.Queued {
+		if !ng.valid(SnakeID(i)) {
+			return fmt.Errorf("Game has no snake %d for queued turns", i)
+		}
+		ng.records[i].turns = ts
+	}
+	for i, n := range gj.Growing {
+		if !ng.valid(SnakeID(i)) {
+			return fmt.Errorf("Game has no snake %d for growth", i)
+		}
+		ng.records[i].grow = n
+	}
+	if err := ng.validateBoard(); err != nil {
+		return err
+	}
+
+	*g = ng
+	return nil
+}
+
+// MarshalJSON the snake facing and segments
+func (s Snake) MarshalJSON() ([]byte, error) {
+	return json.Marshal(snakeJSON{Facing: s.dir, Segments: s.Head()})
+}
+
+// UnmarshalJSON a snake facing and segments
+func (s *Snake) UnmarshalJSON(b []byte) error {
+	sj := snakeJSON{}
+	if err := json.Unmarshal(b, &sj); err != nil {
+		return err
+	}
+	if sj.Segments == nil {
+		return errors.New("Snake has no segments")
+	}
+	*s = *sj.Segments.snake
+	s.dir = sj.Facing
+	return nil
+}
+
+// MarshalJSON the points of a segment and all segments after it
+func (s *Segment) MarshalJSON() ([]byte, error) {
+	ps := []Point{}
+	for c := s; c != nil; c = c.Next() {
+		ps = append(ps, c.Point())
+	}
+	return json.Marshal(ps)
+}
+
+// UnmarshalJSON a segment list from its points, as the head of a new snake
+func (s *Segment) UnmarshalJSON(b []byte) error {
+	ps := []Point{}
+	if err := json.Unmarshal(b, &ps); err != nil {
+		return err
+	}
+	if len(ps) == 0 {
+		return errors.New("Segment list has no points")
+	}
+	sn := snakeFromPoints(ps, Vector{})
+	*s = Segment{snake: &sn}
+	return nil
+}
+
+/**
+ * The binary encoding is a version byte followed by varints.  Snake segments are
+ * written as a head point followed by the step to each next point, which is
+ * almost always a unit vector and so a single byte for each coordinate.
+ *
+ * The version goes up whenever the encoding changes, and only the current version
+ * can be read:
+ *  1. the grid, snakes, food and tick count
+ *  2. the random number state and seed
+ *  3. the snake results
+ *  4. the turn policy
+ *  5. queued turns
+ *  6. food kinds, values and expiry, and growth still to come
+ *  7. scores, score rules, the speed level, and if each snake is in a near-miss
+ */
+
+// version of the binary snapshot encoding
+const snapshotVersion byte = 7
+
+// MarshalBinary the full game state, compactly
+func (g Game) MarshalBinary() ([]byte, error) {
+	b := []byte{snapshotVersion}
+
+	b = binary.AppendVarint(b, int64(g.grid.X))
+	b = binary.AppendVarint(b, int64(g.grid.Y))
+	b = append(b, byte(g.topology))
+	b = binary.AppendUvarint(b, uint64(g.ticks))
+	b = binary.AppendUvarint(b, g.rng.state)
+	b = binary.AppendVarint(b, g.seed)
+	b = append(b, byte(g.turns.Reverse))
+	if g.turns.Diagonals {
+		b = append(b, 1)
+	} else {
+		b = append(b, 0)
+	}
+	b = binary.AppendUvarint(b, uint64(g.turns.Buffer))
+	b = binary.AppendVarint(b, int64(g.speed))
+	for _, k := range foodKinds {
+		b = binary.AppendVarint(b, int64(g.scoring.Food[k]))
+	}
+	b = binary.AppendVarint(b, int64(g.scoring.Streak))
+	b = binary.AppendUvarint(b, uint64(g.scoring.StreakTicks))
+	b = binary.AppendVarint(b, int64(g.scoring.Speed))
+	b = binary.AppendVarint(b, int64(g.scoring.NearMiss))
+	b = binary.AppendVarint(b, int64(g.scoring.Tick))
+	b = binary.AppendUvarint(b, uint64(len(g.foods)))
+	for _, f := range g.foods {
+		b = appendPoint(b, f.Point)
+		b = append(b, byte(f.Kind))
+		b = binary.AppendVarint(b, int64(f.Value))
+		b = binary.AppendUvarint(b, uint64(f.Expires))
+	}
+
+	ws := g.walls.Points()
+	b = binary.AppendUvarint(b, uint64(len(ws)))
+	for _, w := range ws {
+		b = appendPoint(b, w)
+	}
+
+	b = binary.AppendUvarint(b, uint64(len(g.snakes)))
+	for i, s := range g.snakes {
+		b = append(b, byte(g.records[i].end))
+		b = binary.AppendUvarint(b, uint64(g.records[i].ticks))
+		b = binary.AppendUvarint(b, uint64(g.records[i].eaten))
+		b = binary.AppendUvarint(b, uint64(g.records[i].grow))
+		b = appendScore(b, g.records[i].score)
+		b = binary.AppendUvarint(b, uint64(len(g.records[i].turns)))
+		for _, t := range g.records[i].turns {
+			b = appendPoint(b, Point(t))
+		}
+		b = appendPoint(b, Point(s.Facing()))
+
+		ps := s.Points()
+		b = binary.AppendUvarint(b, uint64(len(ps)))
+		b = appendPoint(b, ps[0])
+		for j := 1; j < len(ps); j++ {
+			b = appendPoint(b, Point{X: ps[j].X - ps[j-1].X, Y: ps[j].Y - ps[j-1].Y})
+		}
+	}
+	return b, nil
+}
+
+// UnmarshalBinary a full game state, which is validated
+func (g *Game) UnmarshalBinary(b []byte) error {
+	r := snapshotReader{b: b}
+	if v := r.byte(); v != snapshotVersion {
+		return fmt.Errorf("Unknown game snapshot version %d", v)
+	}
+
+	ng := Game{walls: Obstacles{}}
+	ng.grid = Grid(r.vector())
+	ng.topology = Topology(r.byte())
+	ng.ticks = uint(r.uvarint())
+	ng.rng.state = r.uvarint()
+	ng.seed = r.varint64()
+	ng.turns = TurnPolicy{Reverse: ReversePolicy(r.byte()), Diagonals: r.bool(), Buffer: int(r.uvarint())}
+	ng.speed = r.varint()
+	ng.scoring.Food = map[FoodKind]int{}
+	for _, k := range foodKinds {
+		if n := r.varint(); n != 0 {
+			ng.scoring.Food[k] = n
+		}
+	}
+	ng.scoring.Streak = r.varint()
+	ng.scoring.StreakTicks = uint(r.uvarint())
+	ng.scoring.Speed = r.varint()
+	ng.scoring.NearMiss = r.varint()
+	ng.scoring.Tick = r.varint()
+	for n := r.count(); n > 0 && r.err == nil; n-- {
+		ng.foods = append(ng.foods, Food{Point: Point(r.vector()), Kind: FoodKind(r.byte()), Value: r.varint(), Expires: uint(r.uvarint())})
+	}
+
+	for n := r.count(); n > 0 && r.err == nil; n-- {
+		ng.walls.Add(Point(r.vector()))
+	}
+
+	for n := r.count(); n > 0 && r.err == nil; n-- {
+		rec := snakeRecord{end: EndReason(r.byte()), ticks: uint(r.uvarint()), eaten: uint(r.uvarint()), grow: uint(r.uvarint()), score: r.score()}
+		for t := r.count(); t > 0 && r.err == nil; t-- {
+			rec.turns = append(rec.turns, r.vector())
+		}
+		d := r.vector()
+
+		l := r.count()
+		if l == 0 {
+			r.fail() // every snake has a head
+		}
+		ps := make([]Point, 0, l)
+		p := Point(r.vector())
+		ps = append(ps, p)
+		for j := 1; j < l && r.err == nil; j++ {
+			p = p.Move(r.vector())
+			ps = append(ps, p)
+		}
+		ng.snakes = append(ng.snakes, snakeFromPoints(ps, d))
+		ng.records = append(ng.records, rec)
+	}
+
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.b) > 0 {
+		return fmt.Errorf("Game snapshot has %d bytes left over", len(r.b))
+	}
+	if err := ng.validateBoard(); err != nil {
+		return err
+	}
+
+	*g = ng
+	return nil
+}
+
+// append a score
+func appendScore(b []byte, s Score) []byte {
+	b = binary.AppendVarint(b, int64(s.Points))
+	b = binary.AppendVarint(b, int64(s.Food))
+	b = binary.AppendVarint(b, int64(s.Bonus))
+	b = binary.AppendVarint(b, int64(s.Penalty))
+	b = binary.AppendUvarint(b, uint64(s.Streak))
+	b = binary.AppendUvarint(b, uint64(s.NearMisses))
+	b = binary.AppendUvarint(b, uint64(s.LastFood))
+	if s.Near {
+		return append(b, 1)
+	}
+	return append(b, 0)
+}
+
+// append a point as two varints
+func appendPoint(b []byte, p Point) []byte {
+	b = binary.AppendVarint(b, int64(p.X))
+	return binary.AppendVarint(b, int64(p.Y))
+}
+
+// read binary snapshot values, remembering the first error
+type snapshotReader struct {
+	b   []byte
+	err error
+}
+
+func (r *snapshotReader) fail() {
+	if r.err == nil {
+		r.err = errors.New("Game snapshot is truncated or corrupt")
+	}
+	r.b = nil
+}
+
+func (r *snapshotReader) byte() byte {
+	if len(r.b) == 0 {
+		r.fail()
+		return 0
+	}
+	c := r.b[0]
+	r.b = r.b[1:]
+	return c
+}
+
+// a bool, which must be written as 0 or 1
+func (r *snapshotReader) bool() bool {
+	c := r.byte()
+	if c > 1 {
+		r.fail()
+	}
+	return c == 1
+}
+
+func (r *snapshotReader) varint() int {
+	return int(r.varint64())
+}
+
+func (r *snapshotReader) varint64() int64 {
+	v, n := binary.Varint(r.b)
+	if n <= 0 {
+		r.fail()
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *snapshotReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.fail()
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+// a count of following items, which can't be more than the bytes left
+func (r *snapshotReader) count() int {
+	c := r.uvarint()
+	if c > uint64(len(r.b)) {
+		r.fail()
+		return 0
+	}
+	return int(c)
+}
+
+func (r *snapshotReader) vector() Vector {
+	return Vector{X: r.varint(), Y: r.varint()}
+}
+
+func (r *snapshotReader) score() Score {
+	return Score{Points: r.varint(), Food: r.varint(), Bonus: r.varint(), Penalty: r.varint(), Streak: uint(r.uvarint()), NearMisses: uint(r.uvarint()), LastFood: uint(r.uvarint()), Near: r.bool()}
+}
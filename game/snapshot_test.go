@@ -0,0 +1,163 @@
+package game_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/james-nesbitt/snake/game"
+	"testing"
+)
+
+// make a game that has been played a little, with a dead snake, a snake that is
+// still growing and some food that expires
+func snapshotGame(t *testing.T) game.Game {
+	b := game.NewSnake(game.Point{X: 4, Y: 2}, game.Up)
+	b.Grow() // (4,3)
+	b.Grow() // (4,4)
+	g := multiGame(t, game.NewSnake(game.Point{X: 3, Y: 3}, game.Right), b, game.NewSnake(game.Point{X: 8, Y: 1}, game.Left))
+	g.SetTopology(game.CylinderY)
+	if err := g.SetObstacles(game.NewObstacles(game.Point{X: 1, Y: 8}, game.Point{X: 2, Y: 8})); err != nil {
+		t.Fatalf("Game obstacle error: %s", err)
+	}
+	g.SetFood(game.Point{X: 5, Y: 5})
+	if err := g.AddFood(game.Food{Point: game.Point{X: 7, Y: 1}, Kind: game.GrowFood, Value: 3}); err != nil {
+		t.Fatalf("Game food error: %s", err)
+	}
+
+	g.Tick() // snake 0 runs into snake 1 and snake 2 eats, to grow 2 more
+	if err := g.AddFood(game.Food{Point: game.Point{X: 0, Y: 5}, Kind: game.BonusFood, Value: 5, Expires: 4}); err != nil {
+		t.Fatalf("Game food error: %s", err)
+	}
+	return g
+}
+
+// compare two games by playing them both forward
+func compareGames(t *testing.T, g, rg game.Game) {
+	if g.Ticks() != rg.Ticks() {
+		t.Errorf("Restored game has the wrong tick count: %d / %d", rg.Ticks(), g.Ticks())
+	}
+	if g.Topology() != rg.Topology() || !g.Size().Equals(rg.Size()) || len(g.Obstacles()) != len(rg.Obstacles()) {
+		t.Errorf("Restored game has a different board")
+	}
+	if fmt.Sprint(g.Foods()) != fmt.Sprint(rg.Foods()) {
+		t.Errorf("Restored game has different food: %v / %v", rg.Foods(), g.Foods())
+	}
+
+	g.AddFood(game.NewFood(game.Point{X: 4, Y: 9}))
+	rg.AddFood(game.NewFood(game.Point{X: 4, Y: 9}))
+	for i := 0; i < 8; i++ {
+		rs, err := g.Tick()
+		rrs, rerr := rg.Tick()
+		if (err == nil) != (rerr == nil) || len(rs) != len(rrs) {
+			t.Fatalf("Restored game ticked differently: %v / %v", rrs, rs)
+		}
+		for j := range rs {
+			if rs[j] != rrs[j] {
+				t.Errorf("Restored game had a different tick result: %+v / %+v", rrs[j], rs[j])
+			}
+		}
+	}
+	for _, id := range g.Snakes() {
+		if r, _ := g.Result(id); r != rg.Results()[id] {
+			t.Errorf("Restored snake %d has a different result: %+v / %+v", id, rg.Results()[id], r)
+		}
+		s, _ := g.Snake(id)
+		rs, _ := rg.Snake(id)
+		if g.Alive(id) != rg.Alive(id) || s.Head().String() != rs.Head().String() || s.Facing() != rs.Facing() {
+			t.Errorf("Restored snake %d is different: %s / %s", id, rs.Head(), s.Head())
+		}
+	}
+}
+
+// Test a JSON round trip
+func Test_SnapshotJSON(t *testing.T) {
+	g := snapshotGame(t)
+
+	b, err := json.Marshal(g)
+	if err != nil {
+		t.Fatalf("Error marshalling game: %s", err)
+	}
+	t.Logf("JSON: %s", b)
+
+	rg := game.Game{}
+	if err := json.Unmarshal(b, &rg); err != nil {
+		t.Fatalf("Error unmarshalling game: %s", err)
+	}
+	compareGames(t, g, rg)
+
+	if err := json.Unmarshal([]byte(`{"grid":{"X":4,"Y":4},"snakes":[{"facing":{"X":0,"Y":1},"segments":[{"X":9,"Y":9}]}]}`), &rg); err == nil {
+		t.Errorf("Unmarshalling an invalid game did not produce an error")
+	}
+}
+
+// Test a binary round trip
+func Test_SnapshotBinary(t *testing.T) {
+	g := snapshotGame(t)
+
+	b, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error marshalling game: %s", err)
+	}
+	t.Logf("Binary: %d bytes", len(b))
+
+	rg := game.Game{}
+	if err := rg.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Error unmarshalling game: %s", err)
+	}
+	compareGames(t, g, rg)
+
+	for i := 0; i < len(b); i++ {
+		if err := rg.UnmarshalBinary(b[:i]); err == nil {
+			t.Errorf("Unmarshalling a truncated game (%d bytes) did not produce an error", i)
+		}
+	}
+}
+
+// Test that a JSON snapshot is validated as fully as a new game
+func Test_SnapshotJSONInvalid(t *testing.T) {
+	base := `{"grid":{"X":5,"Y":5},"topology":"walled","snakes":[{"facing":{"X":0,"Y":1},"segments":%s}],` +
+		`"results":[{"snake":0,"reason":%d}],"foods":[{"point":{"X":0,"Y":0},"kind":"normal"}],"turns":{"reverse":%d,"buffer":%d}}`
+	var rg game.Game
+	if err := json.Unmarshal([]byte(fmt.Sprintf(base, `[{"X":2,"Y":2},{"X":2,"Y":1}]`, 0, 0, 0)), &rg); err != nil {
+		t.Fatalf("Unmarshalling a valid game failed: %s", err)
+	}
+
+	bad := map[string]string{
+		"segment outside of the grid": fmt.Sprintf(base, `[{"X":2,"Y":2},{"X":40,"Y":40}]`, 0, 0, 0),
+		"segment at a negative point": fmt.Sprintf(base, `[{"X":-3,"Y":2},{"X":-3,"Y":1}]`, 0, 0, 0),
+		"gap between segments":        fmt.Sprintf(base, `[{"X":2,"Y":2},{"X":2,"Y":0}]`, 0, 0, 0),
+		"overlapping segments":        fmt.Sprintf(base, `[{"X":2,"Y":2},{"X":2,"Y":1},{"X":2,"Y":2}]`, 0, 0, 0),
+		"unknown end reason":          fmt.Sprintf(base, `[{"X":2,"Y":2}]`, 200, 0, 0),
+		"unknown reverse policy":      fmt.Sprintf(base, `[{"X":2,"Y":2}]`, 0, 9, 0),
+		"negative turn buffer":        fmt.Sprintf(base, `[{"X":2,"Y":2}]`, 0, 0, -4),
+	}
+	for name, j := range bad {
+		if err := json.Unmarshal([]byte(j), &rg); err == nil {
+			t.Errorf("Unmarshalling a game with a %s did not produce an error", name)
+		}
+	}
+}
+
+// Test that a binary snapshot is as strict as JSON about what it holds
+func Test_SnapshotBinaryInvalid(t *testing.T) {
+	bad := map[string]func(g *game.Game){
+		"topology":       func(g *game.Game) { g.SetTopology(game.CylinderY + 1) },
+		"reverse policy": func(g *game.Game) { g.SetTurnPolicy(game.TurnPolicy{Reverse: game.AllowReverse + 1}) },
+		"turn buffer":    func(g *game.Game) { g.SetTurnPolicy(game.TurnPolicy{Buffer: -4}) },
+	}
+	for name, f := range bad {
+		g := snapshotGame(t)
+		f(&g)
+		b, _ := g.MarshalBinary()
+		rg := game.Game{}
+		if err := rg.UnmarshalBinary(b); err == nil {
+			t.Errorf("Unmarshalling a game with a bad %s did not produce an error", name)
+		}
+	}
+
+	g := snapshotGame(t)
+	b, _ := g.MarshalBinary()
+	rg := game.Game{}
+	if err := rg.UnmarshalBinary(append(b, 0)); err == nil {
+		t.Errorf("Unmarshalling a game with bytes left over did not produce an error")
+	}
+}
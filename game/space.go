@@ -64,6 +64,55 @@ func (g Grid) Contains(p Point) bool {
 		p.Y <= g.Y
 }
 
+/**
+ * A Topology decides how the edges of a Grid join up.  A point that leaves a
+ * walled grid is outside of it (a boundary collision) but on a wrapping edge it
+ * comes back in on the opposite edge.
+ */
+
+// Topology of a grid, which edges wrap around
+type Topology uint8
+
+const (
+	Walled    Topology = iota // no edges wrap (the classic box)
+	Torus                     // all edges wrap
+	CylinderX                 // only the left and right edges wrap (along X)
+	CylinderY                 // only the top and bottom edges wrap (along Y)
+)
+
+// Convert to a printable string
+func (t Topology) String() string {
+	switch t {
+	case Walled:
+		return "walled"
+	case Torus:
+		return "torus"
+	case CylinderX:
+		return "cylinder-x"
+	case CylinderY:
+		return "cylinder-y"
+	default:
+		return "unknown"
+	}
+}
+
+// Normalize a Point that may have left the grid, by wrapping it across any
+// wrapping edges.  Points that leave across a wall are left outside the grid.
+func (t Topology) Normalize(g Grid, p Point) Point {
+	if t == Torus || t == CylinderX {
+		p.X = wrap(p.X, g.X+1)
+	}
+	if t == Torus || t == CylinderY {
+		p.Y = wrap(p.Y, g.Y+1)
+	}
+	return p
+}
+
+// wrap a coordinate into [0,n)
+func wrap(c, n int) int {
+	return ((c % n) + n) % n
+}
+
 /**
  * A Point is a positional Vector for a point on a grid.  It has no grid awareness
  */
@@ -99,3 +99,38 @@ func Test_PointContains(t *testing.T) {
 		}
 	}
 }
+
+// Test that the topologies wrap points across the right edges
+func Test_TopologyNormalize(t *testing.T) {
+	in := game.Point{X: 4, Y: 7}
+	left := game.Point{X: -1, Y: 7}
+	top := game.Point{X: 4, Y: 11}
+
+	for _, tp := range []game.Topology{game.Walled, game.Torus, game.CylinderX, game.CylinderY} {
+		if n := tp.Normalize(g, in); !n.Equals(in) {
+			t.Errorf("%s topology moved a point that was inside the grid: %s", tp, n)
+		}
+	}
+
+	if n := game.Walled.Normalize(g, left); g.Contains(n) {
+		t.Errorf("Walled topology brought a point back into the grid: %s", n)
+	}
+	if n := game.Torus.Normalize(g, left); !n.Equals(game.Point{X: 10, Y: 7}) {
+		t.Errorf("Torus topology did not wrap left to right: %s", n)
+	}
+	if n := game.Torus.Normalize(g, top); !n.Equals(game.Point{X: 4, Y: 0}) {
+		t.Errorf("Torus topology did not wrap top to bottom: %s", n)
+	}
+	if n := game.CylinderX.Normalize(g, left); !n.Equals(game.Point{X: 10, Y: 7}) {
+		t.Errorf("CylinderX topology did not wrap left to right: %s", n)
+	}
+	if n := game.CylinderX.Normalize(g, top); g.Contains(n) {
+		t.Errorf("CylinderX topology wrapped top to bottom: %s", n)
+	}
+	if n := game.CylinderY.Normalize(g, top); !n.Equals(game.Point{X: 4, Y: 0}) {
+		t.Errorf("CylinderY topology did not wrap top to bottom: %s", n)
+	}
+	if n := game.CylinderY.Normalize(g, left); g.Contains(n) {
+		t.Errorf("CylinderY topology wrapped left to right: %s", n)
+	}
+}
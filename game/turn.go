@@ -0,0 +1,124 @@
+package game
+
+import "fmt"
+
+/**
+ * A snake can be turned to any Vector, but the game only accepts turns that make
+ * sense for play, following its TurnPolicy:
+ *  1. turns must be to one of the four unit vectors, or one of the eight if
+ *     diagonals are allowed
+ *  2. turning straight back (180°) into your own neck can be rejected, ignored
+ *     or allowed.  A snake that is only a head can always turn back.
+ *
+ * A rejected turn is a TurnError, which says why, so that it can be reported
+ * back to whoever asked for the turn.
+ *
+ * Turns can also be buffered.  Without a buffer a turn changes the snake facing
+ * right away, so the last turn before a tick wins.  With a buffer, each snake has
+ * a queue of turns and one is applied on each tick, so that two quick turns
+ * between ticks (like Up then Left for a tight corner) are both made.  Queued
+ * turns are checked against the direction the snake will have when they are
+ * applied, which is the last queued turn.
+ *
+ * Without queued turns, a turn is checked against the way that the snake last
+ * moved (from its neck to its head), and not against its facing, so that two
+ * quick turns between ticks (like Left then Down for a snake going Up) can't
+ * reverse it into its neck.
+ */
+
+// ReversePolicy what to do with a turn straight back into the snake's neck
+type ReversePolicy uint8
+
+const (
+	RejectReverse ReversePolicy = iota // reversing is an error
+	IgnoreReverse                      // reversing does nothing, and isn't an error
+	AllowReverse                       // reversing is allowed (and will collide)
+)
+
+// TurnPolicy which turns the game accepts.  The zero policy accepts only the
+// four unit vectors, and rejects reversing.
+type TurnPolicy struct {
+	Reverse   ReversePolicy `json:"reverse"`
+	Diagonals bool          `json:"diagonals"` // also accept the four diagonal unit vectors
+	Buffer    int           `json:"buffer"`    // how many turns each snake can queue (0 applies turns right away)
+}
+
+// Diagonal unit vectors, which are only turns if the policy allows them
+var (
+	UpRight   Vector = Vector{X: 1, Y: 1}
+	DownRight Vector = Vector{X: 1, Y: -1}
+	DownLeft  Vector = Vector{X: -1, Y: -1}
+	UpLeft    Vector = Vector{X: -1, Y: 1}
+)
+
+// TurnErrorReason why a turn was rejected
+type TurnErrorReason uint8
+
+const (
+	TurnNoSnake   TurnErrorReason = iota // there is no such snake
+	TurnDeadSnake                        // the snake isn't playing any more
+	TurnNotUnit                          // the direction isn't an allowed unit vector
+	TurnReversed                         // the turn goes straight back into the snake
+	TurnQueueFull                        // the snake already has a full queue of turns
+)
+
+// Convert to a printable string
+func (r TurnErrorReason) String() string {
+	switch r {
+	case TurnNoSnake:
+		return "no such snake"
+	case TurnDeadSnake:
+		return "snake is not playing"
+	case TurnNotUnit:
+		return "not an allowed direction"
+	case TurnReversed:
+		return "reverses the snake"
+	case TurnQueueFull:
+		return "too many turns queued"
+	default:
+		return "unknown"
+	}
+}
+
+// TurnError a turn that the game rejected
+type TurnError struct {
+	Snake    SnakeID
+	From, To Vector
+	Reason   TurnErrorReason
+}
+
+// Error as a string
+func (e TurnError) Error() string {
+	return fmt.Sprintf("Snake %d can't turn from %s to %s: %s", e.Snake, e.From, e.To, e.Reason)
+}
+
+// Check a turn from one direction to another, for a snake of some length.  A
+// turn that should be ignored returns false with no error.
+func (tp TurnPolicy) Check(from, to Vector, length uint) (bool, error) {
+	if !tp.unit(to) {
+		return false, TurnError{From: from, To: to, Reason: TurnNotUnit}
+	}
+	if length > 1 && to.Equals(Vector{X: -from.X, Y: -from.Y}) {
+		switch tp.Reverse {
+		case IgnoreReverse:
+			return false, nil
+		case RejectReverse:
+			return false, TurnError{From: from, To: to, Reason: TurnReversed}
+		}
+	}
+	return true, nil
+}
+
+// is a direction one of the allowed unit vectors
+func (tp TurnPolicy) unit(d Vector) bool {
+	ds := []Vector{Up, Right, Down, Left}
+	if tp.Diagonals {
+		ds = append(ds, UpRight, DownRight, DownLeft, UpLeft)
+	}
+	for _, u := range ds {
+		if u.Equals(d) {
+			return true
+		}
+	}
+	return false
+}
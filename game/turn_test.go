@@ -0,0 +1,178 @@
+package game_test
+
+import (
+	"github.com/james-nesbitt/snake/game"
+	"testing"
+)
+
+// Test which directions the turn policies accept
+func Test_TurnPolicyDirections(t *testing.T) {
+	strict := game.TurnPolicy{}
+	diagonal := game.TurnPolicy{Diagonals: true}
+
+	for _, d := range []game.Vector{game.Up, game.Right, game.Down, game.Left} {
+		if ok, err := strict.Check(game.Up, d, 1); !ok || err != nil {
+			t.Errorf("Default turn policy rejected a unit vector %s: %v", d, err)
+		}
+	}
+	for _, d := range []game.Vector{game.UpRight, game.DownRight, game.DownLeft, game.UpLeft} {
+		if _, err := strict.Check(game.Up, d, 1); err == nil {
+			t.Errorf("Default turn policy accepted a diagonal %s", d)
+		}
+		if ok, err := diagonal.Check(game.Up, d, 1); !ok || err != nil {
+			t.Errorf("Diagonal turn policy rejected a diagonal %s: %v", d, err)
+		}
+	}
+	for _, d := range []game.Vector{{X: 3, Y: -2}, {X: 0, Y: 2}, {}} {
+		if _, err := diagonal.Check(game.Up, d, 1); err == nil {
+			t.Errorf("Turn policy accepted a vector that isn't a unit %s", d)
+		} else if te, ok := err.(game.TurnError); !ok || te.Reason != game.TurnNotUnit {
+			t.Errorf("Turn policy produced the wrong error for %s: %s", d, err)
+		}
+	}
+}
+
+// Test what the turn policies do with reversing
+func Test_TurnPolicyReverse(t *testing.T) {
+	if _, err := (game.TurnPolicy{}).Check(game.Up, game.Down, 2); err == nil {
+		t.Errorf("Default turn policy accepted a reverse")
+	} else if te, ok := err.(game.TurnError); !ok || te.Reason != game.TurnReversed {
+		t.Errorf("Turn policy produced the wrong error for a reverse: %s", err)
+	}
+	if ok, err := (game.TurnPolicy{}).Check(game.Up, game.Down, 1); !ok || err != nil {
+		t.Errorf("Default turn policy didn't let a snake head reverse")
+	}
+	if ok, err := (game.TurnPolicy{Reverse: game.IgnoreReverse}).Check(game.Left, game.Right, 4); ok || err != nil {
+		t.Errorf("Ignoring turn policy didn't ignore a reverse")
+	}
+	if ok, err := (game.TurnPolicy{Reverse: game.AllowReverse}).Check(game.Left, game.Right, 4); !ok || err != nil {
+		t.Errorf("Allowing turn policy didn't allow a reverse")
+	}
+}
+
+// Test turning in a game
+func Test_GameTurn(t *testing.T) {
+	tg := testingGame(t)
+	tg.food(5, 6)
+	tg.eat() // (5,5) -> (5,6) [2]
+
+	if err := tg.game.Turn(game.Down); err == nil {
+		t.Errorf("Game let the snake reverse")
+	} else if te, ok := err.(game.TurnError); !ok || te.Snake != 0 || te.From != game.Up || te.To != game.Down {
+		t.Errorf("Game produced the wrong error for a reverse: %s", err)
+	}
+	if tg.game.Facing() != game.Up {
+		t.Errorf("Rejected turn still turned the snake")
+	}
+
+	tg.game.SetTurnPolicy(game.TurnPolicy{Reverse: game.IgnoreReverse})
+	if err := tg.game.Turn(game.Down); err != nil || tg.game.Facing() != game.Up {
+		t.Errorf("Game did not ignore a reverse: %v", err)
+	}
+	if err := tg.game.Turn(game.Up); err != nil {
+		t.Errorf("Game did not allow turning the way the snake already faces: %s", err)
+	}
+
+	if err := tg.game.TurnSnake(3, game.Left); err == nil {
+		t.Errorf("Game turned a snake that doesn't exist")
+	}
+}
+
+// Test that two turns between ticks can't reverse the snake into its neck
+func Test_GameTurnNeck(t *testing.T) {
+	tg := testingGame(t)
+	tg.food(5, 6)
+	tg.eat() // (5,5) -> (5,6) [2]
+	tg.food(5, 7)
+	tg.eat() // (5,6) -> (5,7) [3]
+
+	if err := tg.game.Turn(game.Left); err != nil {
+		t.Errorf("Game rejected a turn: %s", err)
+	}
+	if err := tg.game.Turn(game.Down); err == nil {
+		t.Errorf("Game let the snake turn back into its neck")
+	} else if te, ok := err.(game.TurnError); !ok || te.From != game.Up || te.Reason != game.TurnReversed {
+		t.Errorf("Game produced the wrong error for turning into the neck: %s", err)
+	}
+	if err := tg.game.Turn(game.Right); err != nil {
+		t.Errorf("Game rejected a turn: %s", err)
+	}
+	if rs, _ := tg.game.Tick(); rs[0].Collided() {
+		t.Errorf("Snake collided after turning: %+v", rs[0])
+	}
+}
+
+// Test that the neck is found across a wrapping edge
+func Test_GameTurnNeckWrap(t *testing.T) {
+	g, err := game.NewGame(game.Grid{X: 4, Y: 4}, game.NewSnake(game.Point{X: 4, Y: 2}, game.Right), game.Point{X: 0, Y: 2})
+	if err != nil {
+		t.Fatalf("Error creating game: %s", err)
+	}
+	g.SetTopology(game.Torus)
+	g.Tick() // (4,2) -> (0,2), eating [2]
+
+	g.Turn(game.Up)
+	if err := g.Turn(game.Left); err == nil {
+		t.Errorf("Game let the snake turn back into its neck across the edge")
+	}
+}
+
+// Test that the last turn before a tick wins, without a turn buffer
+func Test_GameTurnLastWins(t *testing.T) {
+	tg := testingGame(t)
+	tg.game.Turn(game.Left)
+	tg.game.Turn(game.Down) // the snake is only a head, so this isn't a reverse
+	tg.move(1)              // (5,5) -> (5,4)
+
+	if !tg.game.HeadPoint().Equals(game.Point{X: 5, Y: 4}) {
+		t.Errorf("Last turn did not win: %s", tg.game.HeadPoint())
+	}
+}
+
+// Test that buffered turns are applied one per tick
+func Test_GameTurnBuffer(t *testing.T) {
+	tg := testingGame(t)
+	tg.game.SetTurnPolicy(game.TurnPolicy{Buffer: 2})
+	tg.food(5, 6)
+	tg.eat() // (5,5) -> (5,6) [2]
+	tg.food(1, 1)
+
+	// A tight corner, right and back down
+	if err := tg.game.Turn(game.Right); err != nil {
+		t.Errorf("Buffered turn was rejected: %s", err)
+	}
+	if err := tg.game.Turn(game.Left); err == nil {
+		t.Errorf("Buffered turn did not check against the queued direction")
+	}
+	if err := tg.game.Turn(game.Down); err != nil {
+		t.Errorf("Buffered turn was rejected: %s", err)
+	}
+	if err := tg.game.Turn(game.Left); err == nil {
+		t.Errorf("Buffered turn was accepted on a full queue")
+	} else if te, ok := err.(game.TurnError); !ok || te.Reason != game.TurnQueueFull {
+		t.Errorf("Buffered turn on a full queue produced the wrong error: %s", err)
+	}
+	if tg.game.Facing() != game.Up || len(tg.game.QueuedTurns(0)) != 2 {
+		t.Errorf("Buffered turns were not queued: %v", tg.game.QueuedTurns(0))
+	}
+
+	// the queue should survive a snapshot
+	b, err := tg.game.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error marshalling game: %s", err)
+	}
+	rg := game.Game{}
+	if err := rg.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Error unmarshalling game: %s", err)
+	}
+	if len(rg.QueuedTurns(0)) != 2 || rg.TurnPolicy().Buffer != 2 {
+		t.Errorf("Restored game lost its queued turns: %v", rg.QueuedTurns(0))
+	}
+
+	tg.move(1) // (5,6) -> (6,6)
+	tg.move(1) // (6,6) -> (6,5)
+	tg.move(1) // (6,5) -> (6,4)
+	if !tg.game.HeadPoint().Equals(game.Point{X: 6, Y: 4}) || len(tg.game.QueuedTurns(0)) != 0 {
+		t.Errorf("Buffered turns were not applied one per tick: %s", tg.game.Head())
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+/**
+ * The html ui: a local web server which hosts snake games for a browser.
+ *
+ * Run it, and open http://localhost:8080 (or whatever -addr says) to play.
+ */
+
+import (
+	"context"
+	"flag"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/store"
+	"github.com/james-nesbitt/snake/web"
+	"log"
+	"net/http"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "the address to serve the games on")
+	width := flag.Int("width", 30, "the grid width")
+	height := flag.Int("height", 30, "the grid height")
+	food := flag.Int("food", 1, "how much food to keep on the board")
+	scores := flag.String("scores", "", "a high score file to keep the results in")
+	flag.Parse()
+
+	cfg := web.Config{Grid: game.Vector{X: *width - 1, Y: *height - 1}, FoodCount: *food}
+	if *scores != "" {
+		st, err := store.Open(*scores)
+		if err != nil {
+			log.Fatalf("Could not open the high score file: %s", err)
+		}
+		cfg.Store = st
+	}
+
+	h := web.NewHost(context.Background(), cfg)
+	log.Printf("Serving snake games on http://%s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, h))
+}
@@ -0,0 +1,207 @@
+package lobby
+
+import (
+	"context"
+	"errors"
+	"github.com/james-nesbitt/snake/game"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/**
+ * A lobby Manager runs many game sessions at once, each with its own server and
+ * its own cancellable context, and finds them by id for the front ends that host
+ * them.
+ *
+ * The manager reaps sessions in the background:
+ *  1. a session that nobody has used for IdleTimeout is ended, and forgotten
+ *  2. a finished session is kept for KeepFinished, so that its results can be
+ *     read, and then forgotten
+ *
+ * At most MaxSessions can be running (not finished) at once.  When the manager
+ * context is done, every session is ended.
+ */
+
+// Config for a manager
+type Config struct {
+	MaxSessions  int           // the most sessions that can run at once (no limit if it isn't set)
+	IdleTimeout  time.Duration // how long a session can go unused before it is ended (never if it isn't set)
+	KeepFinished time.Duration // how long a finished session is kept (until the next reap if it isn't set)
+	ReapEvery    time.Duration // how often to look for sessions to reap (DefaultReapEvery if it isn't set)
+}
+
+// DefaultReapEvery how often a manager reaps, if it isn't told
+const DefaultReapEvery = time.Second
+
+var (
+	// ErrNoSession there is no session with the id that was asked for
+	ErrNoSession = errors.New("Could not find the session")
+	// ErrTooManySessions the manager is already running as many sessions as it can
+	ErrTooManySessions = errors.New("Could not create the session, too many are running")
+	// ErrManagerStopped the manager context is done
+	ErrManagerStopped = errors.New("Could not create the session, the manager has stopped")
+	// ErrSessionFull every snake in the session already has a player
+	ErrSessionFull = errors.New("Could not join the session, it is full")
+	// ErrSessionStarted the session has already started (or ended)
+	ErrSessionStarted = errors.New("Could not join the session, it has started")
+)
+
+// Manager of game sessions
+type Manager struct {
+	cfg Config
+	ctx context.Context
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+	next     int
+}
+
+// NewManager a session manager, which reaps sessions until the context is done
+func NewManager(ctx context.Context, cfg Config) *Manager {
+	if cfg.ReapEvery <= 0 {
+		cfg.ReapEvery = DefaultReapEvery
+	}
+	m := &Manager{cfg: cfg, ctx: ctx, sessions: map[string]*Session{}}
+	go m.reaper()
+	return m
+}
+
+// Create a session for a game.  Set up the session Server, and subscribe to its
+// events, before the session starts.
+func (m *Manager) Create(g *game.Game) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ctx.Err() != nil {
+		return nil, ErrManagerStopped
+	}
+	if m.cfg.MaxSessions > 0 && m.runningLocked() >= m.cfg.MaxSessions {
+		return nil, ErrTooManySessions
+	}
+
+	m.next++
+	s := newSession(m.ctx, strconv.Itoa(m.next), g)
+	m.sessions[s.ID] = s
+	return s, nil
+}
+
+// Get a session by id
+func (m *Manager) Get(id string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, ErrNoSession
+	}
+	return s, nil
+}
+
+// Join a session by id, as its next snake without a player
+func (m *Manager) Join(id, player string) (*Session, game.SnakeID, error) {
+	s, err := m.Get(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	sid, err := s.Join(player)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s, sid, nil
+}
+
+// List the sessions, oldest first
+func (m *Manager) List() []Info {
+	m.mu.Lock()
+	ss := make([]*Session, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		ss = append(ss, s)
+	}
+	m.mu.Unlock()
+
+	is := make([]Info, 0, len(ss))
+	for _, s := range ss {
+		is = append(is, s.Info())
+	}
+	sort.Slice(is, func(i, j int) bool {
+		a, _ := strconv.Atoi(is[i].ID)
+		b, _ := strconv.Atoi(is[j].ID)
+		return a < b
+	})
+	return is
+}
+
+// Running how many sessions haven't finished
+func (m *Manager) Running() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.runningLocked()
+}
+
+func (m *Manager) runningLocked() int {
+	n := 0
+	for _, s := range m.sessions {
+		if _, over := s.Results(); !over {
+			n++
+		}
+	}
+	return n
+}
+
+// End a session by id, stopping its server, and forget it
+func (m *Manager) End(id string) error {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	delete(m.sessions, id)
+	m.mu.Unlock()
+	if !ok {
+		return ErrNoSession
+	}
+	s.end()
+	return nil
+}
+
+// Reap end the idle sessions, and forget them and any finished sessions that
+// have been kept long enough.  It returns the ids of the sessions that it reaped.
+func (m *Manager) Reap() []string {
+	now := time.Now()
+	m.mu.Lock()
+	reaped := []*Session{}
+	for id, s := range m.sessions {
+		if !s.reapable(now, m.cfg) {
+			continue
+		}
+		delete(m.sessions, id)
+		reaped = append(reaped, s)
+	}
+	m.mu.Unlock()
+
+	ids := []string{}
+	for _, s := range reaped {
+		s.end()
+		ids = append(ids, s.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// reap every so often, and end every session once the manager context is done
+func (m *Manager) reaper() {
+	t := time.NewTicker(m.cfg.ReapEvery)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			m.Reap()
+		case <-m.ctx.Done():
+			m.mu.Lock()
+			ss := m.sessions
+			m.sessions = map[string]*Session{}
+			m.mu.Unlock()
+			for _, s := range ss {
+				s.end()
+			}
+			return
+		}
+	}
+}
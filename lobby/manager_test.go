@@ -0,0 +1,147 @@
+package lobby_test
+
+import (
+	"context"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/lobby"
+	"testing"
+	"time"
+)
+
+// Test creating, listing, joining and ending sessions by id
+func Test_ManagerSessions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := lobby.NewManager(ctx, lobby.Config{})
+
+	a, err := m.Create(testGame(t, 1))
+	if err != nil {
+		t.Fatalf("Could not create a session: %s", err)
+	}
+	b, _ := m.Create(testGame(t, 2))
+	if a.ID != "1" || b.ID != "2" {
+		t.Errorf("Sessions have the wrong ids: %s %s", a.ID, b.ID)
+	}
+
+	if s, id, err := m.Join(b.ID, "ann"); err != nil || s != b || id != 0 {
+		t.Errorf("Could not join a session by id: %v %d %s", s, id, err)
+	}
+	if _, _, err := m.Join("7", "ann"); err != lobby.ErrNoSession {
+		t.Errorf("Joined a session that doesn't exist: %v", err)
+	}
+
+	is := m.List()
+	if len(is) != 2 || is[0].ID != "1" || is[1].Players[0] != "ann" || is[1].Started {
+		t.Errorf("Listed the wrong sessions: %+v", is)
+	}
+
+	if err := m.End(a.ID); err != nil {
+		t.Errorf("Could not end a session: %s", err)
+	}
+	if err := m.End(a.ID); err != lobby.ErrNoSession {
+		t.Errorf("Ended a session twice: %v", err)
+	}
+	if _, err := m.Get(a.ID); err != lobby.ErrNoSession {
+		t.Errorf("Ended session was not forgotten: %v", err)
+	}
+	if s, err := m.Get(b.ID); err != nil || s != b {
+		t.Errorf("Could not get a session by id: %v %s", s, err)
+	}
+}
+
+// Test that the manager caps how many sessions are running
+func Test_ManagerMaxSessions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := lobby.NewManager(ctx, lobby.Config{MaxSessions: 1, KeepFinished: time.Hour})
+
+	a, _ := m.Create(testGame(t, 1))
+	if _, err := m.Create(testGame(t, 1)); err != lobby.ErrTooManySessions {
+		t.Fatalf("Created more sessions than the cap: %v", err)
+	}
+
+	// a finished session doesn't count, even while it is kept
+	a.Join("ann")
+	for i := 0; i < 10; i++ {
+		a.Server.Tick(ctx)
+	}
+	waitDone(t, a)
+	if m.Running() != 0 {
+		t.Errorf("Finished session is still running: %d", m.Running())
+	}
+	if _, err := m.Create(testGame(t, 1)); err != nil {
+		t.Errorf("Could not create a session once the last finished: %s", err)
+	}
+	if _, err := m.Get(a.ID); err != nil {
+		t.Errorf("Finished session was not kept: %s", err)
+	}
+}
+
+// Test that idle and finished sessions are reaped
+func Test_ManagerReap(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := lobby.NewManager(ctx, lobby.Config{IdleTimeout: 50 * time.Millisecond, ReapEvery: time.Hour})
+
+	idle, _ := m.Create(testGame(t, 1))
+	busy, _ := m.Create(testGame(t, 1))
+	done, _ := m.Create(testGame(t, 1))
+	done.Join("ann")
+	for i := 0; i < 10; i++ {
+		done.Server.Tick(ctx)
+	}
+	waitDone(t, done)
+
+	time.Sleep(30 * time.Millisecond)
+	busy.Touch()
+	time.Sleep(30 * time.Millisecond)
+
+	if ids := m.Reap(); len(ids) != 2 || ids[0] != idle.ID || ids[1] != done.ID {
+		t.Errorf("Reaped the wrong sessions: %v", ids)
+	}
+	if idle.Context().Err() == nil {
+		t.Errorf("Idle session was not ended")
+	}
+	if is := m.List(); len(is) != 1 || is[0].ID != busy.ID {
+		t.Errorf("Kept the wrong sessions: %+v", is)
+	}
+}
+
+// Test that the manager reaps by itself, and ends every session when it stops
+func Test_ManagerStop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := lobby.NewManager(ctx, lobby.Config{IdleTimeout: 10 * time.Millisecond, ReapEvery: 5 * time.Millisecond})
+
+	idle, _ := m.Create(testGame(t, 1))
+	waitDone(t, idle)
+
+	s, _ := m.Create(testGame(t, 1))
+	s.Join("ann")
+	cancel()
+	waitDone(t, s)
+	if _, err := m.Create(testGame(t, 1)); err != lobby.ErrManagerStopped {
+		t.Errorf("Created a session after the manager stopped: %v", err)
+	}
+}
+
+// Test that a session that is being played isn't reaped as idle
+func Test_ManagerReapPlayed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := lobby.NewManager(ctx, lobby.Config{IdleTimeout: 50 * time.Millisecond, ReapEvery: 5 * time.Millisecond})
+
+	g := testGame(t, 1)
+	g.SetTopology(game.Torus)
+	s, _ := m.Create(g)
+	s.Join("ann")
+	for i := 0; i < 20; i++ {
+		if err := s.Server.Tick(ctx); err != nil {
+			t.Fatalf("Could not tick the game: %s", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := m.Get(s.ID); err != nil {
+		t.Errorf("Reaped a session that was being played: %s", err)
+	}
+	waitDone(t, s)
+}
@@ -0,0 +1,241 @@
+package lobby
+
+import (
+	"context"
+	"fmt"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/server"
+	"sync"
+	"time"
+)
+
+/**
+ * A Session is one game, its server and its players.
+ *
+ * Players join the session one snake at a time, and the server starts once every
+ * snake has a player (or when Start is called, to play with empty snakes).  Until
+ * then the Server fields (like the clock, the store or a recorder) can be set, and
+ * event feeds subscribed.  The session context is cancelled when the session is
+ * ended, which stops the server.
+ *
+ * A started session watches its own server events, so that playing the game
+ * keeps it from being reaped as idle: turns, pauses and ticks all count, except
+ * for the ticks of the server clock, so that a game that nobody plays still
+ * goes idle.
+ */
+
+// Session a game and its players, run by a Manager
+type Session struct {
+	ID       string
+	Game     *game.Game
+	Server   *server.Server
+	MakeFood server.MakeFood // makes the food on the server loop, once the session starts (random food if it isn't set)
+
+	ctx     context.Context
+	cancel  func()
+	created time.Time
+
+	mu       sync.Mutex
+	players  []string // the player for each snake, "" until it has joined
+	joined   []bool   // which snakes have a player
+	started  bool
+	ended    bool
+	active   time.Time         // the last time that the session was used
+	finished time.Time         // when the session finished, zero until it has
+	results  []game.GameResult // the final results, once it has finished
+	done     chan struct{}     // closed once the session has finished
+}
+
+// Info about a session, for listing
+type Info struct {
+	ID       string
+	Players  []string // the player for each snake, "" until it has joined
+	Started  bool
+	Finished bool
+	Created  time.Time
+	Active   time.Time // the last time that the session was used
+}
+
+// a new session for a game, which hasn't started
+func newSession(ctx context.Context, id string, g *game.Game) *Session {
+	srv := server.NewServer(g)
+	ctx, cancel := context.WithCancel(ctx)
+	now := time.Now()
+	n := len(g.Snakes())
+	return &Session{
+		ID:      id,
+		Game:    g,
+		Server:  &srv,
+		ctx:     ctx,
+		cancel:  cancel,
+		created: now,
+		players: make([]string, n),
+		joined:  make([]bool, n),
+		active:  now,
+		done:    make(chan struct{}),
+	}
+}
+
+// Context the session context, which is done once the session is ended
+func (s *Session) Context() context.Context {
+	return s.ctx
+}
+
+// Done closed once the session has finished, and its results are kept
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Join the session as the next snake without a player.  The session starts once
+// every snake has one.
+func (s *Session) Join(player string) (game.SnakeID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.ended {
+		return 0, ErrSessionStarted
+	}
+
+	id := -1
+	for i, j := range s.joined {
+		if !j {
+			id = i
+			break
+		}
+	}
+	if id < 0 {
+		return 0, ErrSessionFull
+	}
+	if player == "" {
+		player = fmt.Sprintf("player %d", id+1)
+	}
+	s.joined[id], s.players[id] = true, player
+	s.active = time.Now()
+
+	if id == len(s.joined)-1 {
+		s.startLocked()
+	}
+	return game.SnakeID(id), nil
+}
+
+// Leave free a snake for someone else, if the session hasn't started
+func (s *Session) Leave(id game.SnakeID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || int(id) < 0 || int(id) >= len(s.joined) {
+		return
+	}
+	s.joined[id], s.players[id] = false, ""
+	s.active = time.Now()
+}
+
+// Start the session without waiting for every snake to have a player
+func (s *Session) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.ended {
+		return ErrSessionStarted
+	}
+	s.startLocked()
+	return nil
+}
+
+// start the server, and keep the results once it stops
+func (s *Session) startLocked() {
+	s.started = true
+	s.active = time.Now()
+	if s.Server.StoreInfo.Players == nil {
+		s.Server.StoreInfo.Players = append([]string{}, s.players...)
+	}
+	if s.Server.MakeFood == nil {
+		s.Server.MakeFood = s.MakeFood
+	}
+	s.Server.RandomFood()
+	go s.watch(s.Server.Subscribe(0))
+	go s.Server.Start(s.ctx)
+	go func() {
+		rs := []game.GameResult{}
+		for r := range s.Server.Finished {
+			rs = append(rs, r)
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.finishLocked(rs)
+	}()
+}
+
+// touch the session for every event that a player caused, until the server stops
+func (s *Session) watch(sub *server.Subscription) {
+	clock := false
+	for e := range sub.C {
+		switch e.Type {
+		case server.EventClock:
+			clock = e.Interval > 0
+		case server.EventTicked:
+			if !clock {
+				s.Touch()
+			}
+		case server.EventTurned, server.EventTurnRejected, server.EventPaused, server.EventResumed:
+			s.Touch()
+		}
+	}
+}
+
+// end the session, stopping its server
+func (s *Session) end() {
+	s.cancel()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started && !s.ended {
+		// there is no server to wait for
+		s.finishLocked([]game.GameResult{})
+	}
+	s.ended = true
+}
+
+// should the session be reaped: it has been finished for long enough, or it
+// hasn't been used for too long
+func (s *Session) reapable(now time.Time, cfg Config) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.finished.IsZero() {
+		return now.Sub(s.finished) >= cfg.KeepFinished
+	}
+	return cfg.IdleTimeout > 0 && now.Sub(s.active) >= cfg.IdleTimeout
+}
+
+// mark the session finished, with its results
+func (s *Session) finishLocked(rs []game.GameResult) {
+	s.results = rs
+	s.finished = time.Now()
+	close(s.done)
+}
+
+// Touch mark the session as in use, so that it isn't reaped as idle.  Playing the
+// game touches the session, but front ends should touch it whenever a player
+// does something else (like watching it).
+func (s *Session) Touch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active = time.Now()
+}
+
+// Results the final results, and true once the session has finished
+func (s *Session) Results() ([]game.GameResult, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.results, !s.finished.IsZero()
+}
+
+// Info about the session
+func (s *Session) Info() Info {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Info{
+		ID:       s.ID,
+		Players:  append([]string{}, s.players...),
+		Started:  s.started,
+		Finished: !s.finished.IsZero(),
+		Created:  s.created,
+		Active:   s.active,
+	}
+}
@@ -0,0 +1,105 @@
+package lobby_test
+
+import (
+	"context"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/lobby"
+	"testing"
+	"time"
+)
+
+// a game for some snakes, spread across a 10x10 grid facing up
+func testGame(t *testing.T, snakes int) *game.Game {
+	ss := []game.Snake{}
+	for i := 0; i < snakes; i++ {
+		ss = append(ss, game.NewSnake(game.Point{X: 2 + 3*i, Y: 4}, game.Up))
+	}
+	g, err := game.NewMultiGame(game.Grid{X: 9, Y: 9}, ss, game.Point{X: 1, Y: 8})
+	if err != nil {
+		t.Fatalf("Could not make a test game: %s", err)
+	}
+	return &g
+}
+
+// wait for a session to finish
+func waitDone(t *testing.T, s *lobby.Session) {
+	select {
+	case <-s.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Session %s did not finish", s.ID)
+	}
+}
+
+// Test that a session starts once every snake has a player, and keeps its results
+func Test_SessionJoin(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := lobby.NewManager(ctx, lobby.Config{})
+	s, _ := m.Create(testGame(t, 2))
+	sub := s.Server.Subscribe(0)
+
+	if id, err := s.Join("ann"); err != nil || id != 0 {
+		t.Fatalf("Could not join as the first snake: %d %s", id, err)
+	}
+	s.Leave(0)
+	if id, err := s.Join("ann"); err != nil || id != 0 {
+		t.Fatalf("Could not join a snake that was left: %d %s", id, err)
+	}
+	if s.Info().Started {
+		t.Errorf("Session started before every snake had a player")
+	}
+	if id, err := s.Join(""); err != nil || id != 1 {
+		t.Fatalf("Could not join as the second snake: %d %s", id, err)
+	}
+	if i := s.Info(); !i.Started || i.Players[0] != "ann" || i.Players[1] != "player 2" {
+		t.Errorf("Session did not start with its players: %+v", i)
+	}
+	if _, err := s.Join("bob"); err != lobby.ErrSessionStarted {
+		t.Errorf("Joined a session that had started: %v", err)
+	}
+	if err := s.Start(); err != lobby.ErrSessionStarted {
+		t.Errorf("Started a session twice: %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		s.Server.Tick(ctx)
+	}
+	waitDone(t, s)
+	if rs, over := s.Results(); !over || len(rs) != 2 || rs[0].Reason != game.HitBoundary {
+		t.Errorf("Session has the wrong results: %v %+v", over, rs)
+	}
+	if _, ok := <-sub.C; !ok {
+		t.Errorf("Subscription made before the session started got no events")
+	}
+}
+
+// Test that a session can start with empty snakes, and that ending one stops it
+func Test_SessionStartEnd(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := lobby.NewManager(ctx, lobby.Config{})
+
+	s, _ := m.Create(testGame(t, 2))
+	s.Join("ann")
+	if err := s.Start(); err != nil {
+		t.Fatalf("Could not start a session early: %s", err)
+	}
+	if err := s.Server.Tick(ctx); err != nil {
+		t.Errorf("Session server is not running: %s", err)
+	}
+	if err := m.End(s.ID); err != nil {
+		t.Fatalf("Could not end a session: %s", err)
+	}
+	waitDone(t, s)
+	if s.Context().Err() == nil {
+		t.Errorf("Ended session context is not done")
+	}
+
+	// a session that never started finishes as soon as it is ended
+	u, _ := m.Create(testGame(t, 1))
+	m.End(u.ID)
+	waitDone(t, u)
+	if rs, over := u.Results(); !over || len(rs) != 0 {
+		t.Errorf("Unstarted session has the wrong results: %v %+v", over, rs)
+	}
+}
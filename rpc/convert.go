@@ -0,0 +1,116 @@
+package rpc
+
+import (
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/rpc/snakepb"
+	"github.com/james-nesbitt/snake/server"
+)
+
+/**
+ * Conversions between the game and server types, and their protobuf messages.
+ * The food kind and end reason enums are in the same order as the game ones, and
+ * the event types are in the server order after an UNSPECIFIED zero.
+ */
+
+// the directions that the protocol has names for
+var directions = map[snakepb.Direction]game.Vector{
+	snakepb.Direction_DIRECTION_UP:    game.Up,
+	snakepb.Direction_DIRECTION_DOWN:  game.Down,
+	snakepb.Direction_DIRECTION_LEFT:  game.Left,
+	snakepb.Direction_DIRECTION_RIGHT: game.Right,
+}
+
+// the game direction for a protocol direction
+func direction(d snakepb.Direction) (game.Vector, bool) {
+	v, ok := directions[d]
+	return v, ok
+}
+
+// the protocol direction for a game direction, UNSPECIFIED if it hasn't got one
+func directionMessage(v game.Vector) snakepb.Direction {
+	for d, dv := range directions {
+		if dv.Equals(v) {
+			return d
+		}
+	}
+	return snakepb.Direction_DIRECTION_UNSPECIFIED
+}
+
+func pointMessage(p game.Point) *snakepb.Point {
+	return &snakepb.Point{X: int32(p.X), Y: int32(p.Y)}
+}
+
+func pointMessages(ps []game.Point) []*snakepb.Point {
+	ms := []*snakepb.Point{}
+	for _, p := range ps {
+		ms = append(ms, pointMessage(p))
+	}
+	return ms
+}
+
+func foodMessage(f game.Food) *snakepb.Food {
+	return &snakepb.Food{Point: pointMessage(f.Point), Kind: snakepb.FoodKind(f.Kind), Value: int32(f.Value), Expires: uint32(f.Expires)}
+}
+
+func resultMessages(rs []game.GameResult) []*snakepb.Result {
+	ms := []*snakepb.Result{}
+	for _, r := range rs {
+		ms = append(ms, &snakepb.Result{
+			Snake:     int32(r.Snake),
+			Reason:    snakepb.EndReason(r.Reason),
+			Length:    uint32(r.Length),
+			Ticks:     uint32(r.Ticks),
+			FoodEaten: uint32(r.FoodEaten),
+			Score:     int32(r.Score),
+		})
+	}
+	return ms
+}
+
+// the whole game frame.  Only read the game on the server loop, or once the
+// server has stopped.
+func stateMessage(id string, g *game.Game) *snakepb.GameState {
+	st := &snakepb.GameState{
+		GameId: id,
+		Tick:   uint32(g.Ticks()),
+		Size:   pointMessage(game.Point(g.Size())),
+		Walls:  pointMessages(g.Obstacles().Points()),
+		Speed:  int32(g.Speed()),
+		Over:   g.Over(),
+	}
+	for _, id := range g.Snakes() {
+		sn, _ := g.Snake(id)
+		sc, _ := g.Score(id)
+		st.Snakes = append(st.Snakes, &snakepb.Snake{
+			Id:     int32(id),
+			Points: pointMessages(sn.Points()),
+			Facing: directionMessage(sn.Facing()),
+			Alive:  g.Alive(id),
+			Score:  int32(sc.Points),
+		})
+	}
+	for _, f := range g.Foods() {
+		st.Foods = append(st.Foods, foodMessage(f))
+	}
+	return st
+}
+
+func eventMessage(e server.Event) *snakepb.Event {
+	m := &snakepb.Event{
+		Seq:        e.Seq,
+		Tick:       uint32(e.Tick),
+		Type:       snakepb.EventType(e.Type + 1),
+		Snake:      int32(e.Snake),
+		Point:      pointMessage(e.Point),
+		Dir:        directionMessage(e.Dir),
+		Food:       foodMessage(e.Food),
+		Points:     int32(e.Points),
+		Reason:     snakepb.EndReason(e.Reason),
+		Speed:      int32(e.Speed),
+		IntervalMs: e.Interval.Milliseconds(),
+	}
+	if e.Err != nil {
+		m.Error = e.Err.Error()
+	}
+	return m
+}
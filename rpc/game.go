@@ -0,0 +1,79 @@
+package rpc
+
+import (
+	"context"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/rpc/snakepb"
+	"github.com/james-nesbitt/snake/server"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+/**
+ * An rpc game is a game, and the server that runs it.  Once the server stops the
+ * final results are kept, so that the game can still be asked about, until the
+ * service forgets it.
+ */
+
+// a game, and its server
+type rpcGame struct {
+	id     string
+	srv    *server.Server
+	snakes int  // how many snakes the game has
+	clock  bool // the game runs on the server clock
+
+	cancel   func()
+	finished chan struct{}     // closed once the server has stopped, and results is set
+	results  []game.GameResult // the final results
+}
+
+// a new game, with its server started
+func newRPCGame(s *Service, id string, g *game.Game, req *snakepb.CreateGameRequest) *rpcGame {
+	srv := server.NewServer(g)
+	srv.FoodCount = int(req.FoodCount)
+	srv.SelfClock = req.Clock
+	srv.ClockRules = s.cfg.Clock
+	srv.SpectatorDelay = s.cfg.SpectatorDelay
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	rg := &rpcGame{
+		id:       id,
+		srv:      &srv,
+		snakes:   len(g.Snakes()),
+		clock:    req.Clock,
+		cancel:   cancel,
+		finished: make(chan struct{}),
+	}
+
+	srv.RandomFood()
+	go srv.Start(ctx)
+	go rg.finish(s)
+	return rg
+}
+
+// keep the results once the server stops, and have the service forget the game
+// after KeepFinished
+func (rg *rpcGame) finish(s *Service) {
+	rs := []game.GameResult{}
+	for r := range rg.srv.Finished {
+		rs = append(rs, r)
+	}
+	rg.results = rs
+	close(rg.finished)
+	time.AfterFunc(s.cfg.KeepFinished, func() { s.forget(rg) })
+}
+
+// the game state.  It is read on the server loop, so it is taken between ticks,
+// and after any commands that have already been sent.
+func (rg *rpcGame) state(ctx context.Context) (*snakepb.GameState, error) {
+	var st *snakepb.GameState
+	final, err := rg.srv.ViewFinal(ctx, func(g *game.Game) { st = stateMessage(rg.id, g) })
+	if err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	if final {
+		<-rg.finished
+		st.Results = resultMessages(rg.results)
+	}
+	return st, nil
+}
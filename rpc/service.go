@@ -0,0 +1,310 @@
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative snakepb/snake.proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/rpc/snakepb"
+	"github.com/james-nesbitt/snake/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/**
+ * An rpc Service is the gRPC snake service (snakepb/snake.proto), which runs each
+ * game on its own server.Server for remote clients:
+ *   CreateGame  : a new game, started right away
+ *   Turn        : turn a snake
+ *   Step        : tick the game once (a game on the server clock is paused first)
+ *   GetState    : the whole game frame
+ *   WatchEvents : the server events, until the game is over
+ *   EndGame     : stop the game, and forget it
+ *
+ * A game is kept, with its final state, until EndGame, until KeepFinished after
+ * its server stops or until the service context is done, and at most MaxGames
+ * can be kept at once.  Register the service on a grpc.Server, and use the generated
+ * snakepb.SnakeServiceClient to call it:
+ *
+ *   snakepb.RegisterSnakeServiceServer(gs, rpc.NewService(ctx, rpc.Config{}))
+ */
+
+// Config for the games that a service creates
+type Config struct {
+	Clock          server.ClockRules // how fast the games on the server clock are (server.DefaultClockRules if they aren't set)
+	SpectatorDelay uint              // how many ticks behind the game WatchEvents streams are kept (live if it isn't set)
+	MaxGames       int               // the most games that can be kept at once, running or finished (DefaultMaxGames if it isn't set)
+	KeepFinished   time.Duration     // how long a game is kept once its server stops (DefaultKeepFinished if it isn't set)
+}
+
+const (
+	// DefaultMaxGames how many games a service keeps at once, if it isn't told
+	DefaultMaxGames = 100
+	// DefaultKeepFinished how long a service keeps a finished game, if it isn't told
+	DefaultKeepFinished = time.Minute
+)
+
+const (
+	defaultSize   = 20  // the grid width and height, if they aren't set
+	maxSize       = 200 // the largest grid width and height
+	maxSnakes     = 8   // the most snakes a game can have
+	maxFood       = 100 // the most food a game can keep on the board
+	watchBuffer   = 256
+	watchedHeader = "snake-watching"
+)
+
+// Service the gRPC snake service
+type Service struct {
+	snakepb.UnimplementedSnakeServiceServer
+
+	cfg Config
+	ctx context.Context
+
+	mu    sync.Mutex
+	games map[string]*rpcGame
+	next  int
+}
+
+// NewService a snake service, which stops all of its games when the context is
+// done
+func NewService(ctx context.Context, cfg Config) *Service {
+	if cfg.MaxGames <= 0 {
+		cfg.MaxGames = DefaultMaxGames
+	}
+	if cfg.KeepFinished <= 0 {
+		cfg.KeepFinished = DefaultKeepFinished
+	}
+	return &Service{cfg: cfg, ctx: ctx, games: map[string]*rpcGame{}}
+}
+
+var (
+	// ErrNoGame there is no game with the id that was asked for
+	ErrNoGame = errors.New("Could not find the game")
+	// ErrTooManyGames the service already has as many games as it can keep
+	ErrTooManyGames = errors.New("Could not create the game, there are too many games")
+)
+
+// CreateGame a new game, and start its server
+func (s *Service) CreateGame(ctx context.Context, req *snakepb.CreateGameRequest) (*snakepb.CreateGameResponse, error) {
+	g, err := newGame(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.mu.Lock()
+	if len(s.games) >= s.cfg.MaxGames {
+		s.mu.Unlock()
+		return nil, status.Error(codes.ResourceExhausted, ErrTooManyGames.Error())
+	}
+	s.next++
+	id := strconv.Itoa(s.next)
+	rg := newRPCGame(s, id, &g, req)
+	s.games[id] = rg
+	s.mu.Unlock()
+	log.Printf("RPC: Created game %s", id)
+
+	st, err := rg.state(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &snakepb.CreateGameResponse{GameId: id, State: st}, nil
+}
+
+// Turn a snake.  The game checks the turn when it plays it, so a rejected turn is
+// a turn-rejected event rather than an error.
+func (s *Service) Turn(ctx context.Context, req *snakepb.TurnRequest) (*snakepb.TurnResponse, error) {
+	rg, err := s.game(req.GameId)
+	if err != nil {
+		return nil, err
+	}
+	d, ok := direction(req.Dir)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "Could not turn, %s is not a direction", req.Dir)
+	}
+	if req.Snake < 0 || int(req.Snake) >= rg.snakes {
+		return nil, status.Errorf(codes.InvalidArgument, "Could not turn, the game has no snake %d", req.Snake)
+	}
+	if err := rg.srv.TurnSnake(ctx, game.SnakeID(req.Snake), d); err != nil {
+		return nil, statusErr(err)
+	}
+	return &snakepb.TurnResponse{}, nil
+}
+
+// Step tick a game once, and return the state after the tick
+func (s *Service) Step(ctx context.Context, req *snakepb.StepRequest) (*snakepb.StepResponse, error) {
+	rg, err := s.game(req.GameId)
+	if err != nil {
+		return nil, err
+	}
+	if rg.clock {
+		err = rg.srv.Step(ctx)
+	} else {
+		err = rg.srv.Tick(ctx)
+	}
+	if err != nil {
+		return nil, statusErr(err)
+	}
+	st, err := rg.state(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &snakepb.StepResponse{State: st}, nil
+}
+
+// GetState the state of a game
+func (s *Service) GetState(ctx context.Context, req *snakepb.GetStateRequest) (*snakepb.GetStateResponse, error) {
+	rg, err := s.game(req.GameId)
+	if err != nil {
+		return nil, err
+	}
+	st, err := rg.state(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &snakepb.GetStateResponse{State: st}, nil
+}
+
+// WatchEvents stream the events of a game, until it is over.  A watch is a
+// server spectator, so it is kept the SpectatorDelay behind the game.  The stream
+// header is sent once the watch has subscribed, so a client that waits for it
+// won't miss any later events.  Events that the client is too slow for are dropped,
+// which shows up as a gap in the sequence numbers.
+func (s *Service) WatchEvents(req *snakepb.WatchEventsRequest, stream snakepb.SnakeService_WatchEventsServer) error {
+	rg, err := s.game(req.GameId)
+	if err != nil {
+		return err
+	}
+	ctx := stream.Context()
+	sp, err := rg.srv.Spectate(ctx, watchBuffer)
+	if err != nil {
+		return status.FromContextError(err).Err()
+	}
+	sub := sp.Subscription
+	defer sub.Unsubscribe()
+	if err := stream.SendHeader(metadata.Pairs(watchedHeader, rg.id)); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(eventMessage(e)); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// EndGame stop a game and forget it, returning its results
+func (s *Service) EndGame(ctx context.Context, req *snakepb.EndGameRequest) (*snakepb.EndGameResponse, error) {
+	s.mu.Lock()
+	rg, ok := s.games[req.GameId]
+	delete(s.games, req.GameId)
+	s.mu.Unlock()
+	if !ok {
+		return nil, status.Error(codes.NotFound, ErrNoGame.Error())
+	}
+
+	rg.cancel()
+	select {
+	case <-rg.finished:
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	log.Printf("RPC: Ended game %s", rg.id)
+	return &snakepb.EndGameResponse{Results: resultMessages(rg.results)}, nil
+}
+
+// forget a game, if it is still kept
+func (s *Service) forget(rg *rpcGame) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.games[rg.id] == rg {
+		delete(s.games, rg.id)
+		log.Printf("RPC: Forgot finished game %s", rg.id)
+	}
+}
+
+// the game with an id
+func (s *Service) game(id string) (*rpcGame, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rg, ok := s.games[id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, ErrNoGame.Error())
+	}
+	return rg, nil
+}
+
+// a gRPC status for a server command error
+func statusErr(err error) error {
+	switch err {
+	case server.ErrGameOver:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case server.ErrStopped:
+		return status.Error(codes.Unavailable, err.Error())
+	case context.Canceled, context.DeadlineExceeded:
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// newGame a game for a request: from its level, or with the snakes spread across
+// the middle of an empty grid, all facing up, and food above them
+func newGame(req *snakepb.CreateGameRequest) (game.Game, error) {
+	if req.FoodCount < 0 || req.FoodCount > maxFood {
+		return game.Game{}, fmt.Errorf("Could not create the game, the food count must be from 0 to %d", maxFood)
+	}
+	if req.Level != "" {
+		g, err := game.ReadLevel(strings.NewReader(req.Level))
+		if err != nil {
+			return g, err
+		}
+		if sz := g.Size(); sz.X >= maxSize || sz.Y >= maxSize {
+			return game.Game{}, fmt.Errorf("Could not create the game, the level is bigger than %dx%d", maxSize, maxSize)
+		}
+		return g, nil
+	}
+
+	w, h, n := int(req.Width), int(req.Height), int(req.Snakes)
+	if w == 0 {
+		w = defaultSize
+	}
+	if h == 0 {
+		h = defaultSize
+	}
+	if n == 0 {
+		n = 1
+	}
+	if w < 2 || h < 2 {
+		return game.Game{}, errors.New("Could not create the game, the grid is too small")
+	}
+	if w > maxSize || h > maxSize {
+		return game.Game{}, fmt.Errorf("Could not create the game, the grid is bigger than %dx%d", maxSize, maxSize)
+	}
+	if n < 1 || n > maxSnakes || n > w {
+		return game.Game{}, errors.New("Could not create the game, it has the wrong number of snakes")
+	}
+
+	grid := game.Vector{X: w - 1, Y: h - 1}
+	ss := []game.Snake{}
+	for i := 0; i < n; i++ {
+		p := game.Point{X: (i + 1) * w / (n + 1), Y: grid.Y / 2}
+		ss = append(ss, game.NewSnake(p, game.Up))
+	}
+	return game.NewMultiGame(game.Grid(grid), ss, game.Point{X: grid.X / 2, Y: grid.Y * 3 / 4})
+}
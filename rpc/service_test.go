@@ -0,0 +1,291 @@
+package rpc_test
+
+import (
+	"context"
+	"github.com/james-nesbitt/snake/rpc"
+	"github.com/james-nesbitt/snake/rpc/snakepb"
+	"github.com/james-nesbitt/snake/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// a client for a service on an in-process listener
+func testClient(t *testing.T, cfg rpc.Config) (context.Context, snakepb.SnakeServiceClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	l := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	snakepb.RegisterSnakeServiceServer(gs, rpc.NewService(ctx, cfg))
+	go gs.Serve(l)
+	t.Cleanup(gs.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Could not create a client: %s", err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return ctx, snakepb.NewSnakeServiceClient(cc)
+}
+
+// watch the events of a game, once the watch has subscribed
+func watch(t *testing.T, ctx context.Context, c snakepb.SnakeServiceClient, id string) snakepb.SnakeService_WatchEventsClient {
+	w, err := c.WatchEvents(ctx, &snakepb.WatchEventsRequest{GameId: id})
+	if err != nil {
+		t.Fatalf("Could not watch the game: %s", err)
+	}
+	if _, err := w.Header(); err != nil {
+		t.Fatalf("Watch did not subscribe: %s", err)
+	}
+	return w
+}
+
+// the types of the events on a watch, until it ends
+func watchedTypes(t *testing.T, w snakepb.SnakeService_WatchEventsClient) []snakepb.EventType {
+	ets := []snakepb.EventType{}
+	for {
+		e, err := w.Recv()
+		if err == io.EOF {
+			return ets
+		}
+		if err != nil {
+			t.Fatalf("Watch failed: %s", err)
+		}
+		ets = append(ets, e.Type)
+	}
+}
+
+// is an event type in a list
+func watched(ets []snakepb.EventType, et snakepb.EventType) bool {
+	for _, e := range ets {
+		if e == et {
+			return true
+		}
+	}
+	return false
+}
+
+// the gRPC code of an error
+func code(err error) codes.Code {
+	return status.Code(err)
+}
+
+// Test a game played over the service, until the snake hits the boundary
+func Test_ServiceGame(t *testing.T) {
+	ctx, c := testClient(t, rpc.Config{})
+
+	cr, err := c.CreateGame(ctx, &snakepb.CreateGameRequest{Width: 10, Height: 10})
+	if err != nil {
+		t.Fatalf("Could not create a game: %s", err)
+	}
+	st := cr.State
+	if cr.GameId != "1" || st.Size.X != 9 || st.Size.Y != 9 || len(st.Snakes) != 1 || len(st.Foods) != 1 {
+		t.Fatalf("Created the wrong game: %+v", cr)
+	}
+	if h := st.Snakes[0].Points[0]; h.X != 5 || h.Y != 4 || st.Snakes[0].Facing != snakepb.Direction_DIRECTION_UP {
+		t.Errorf("Snake started in the wrong place: %+v", st.Snakes[0])
+	}
+
+	w := watch(t, ctx, c, cr.GameId)
+	if _, err := c.Turn(ctx, &snakepb.TurnRequest{GameId: cr.GameId, Dir: snakepb.Direction_DIRECTION_LEFT}); err != nil {
+		t.Fatalf("Could not turn: %s", err)
+	}
+	sr, err := c.Step(ctx, &snakepb.StepRequest{GameId: cr.GameId})
+	if err != nil {
+		t.Fatalf("Could not step: %s", err)
+	}
+	if sn := sr.State.Snakes[0]; sr.State.Tick != 1 || sn.Points[0].X != 4 || sn.Facing != snakepb.Direction_DIRECTION_LEFT {
+		t.Errorf("Step did not move the snake left: %+v", sr.State)
+	}
+
+	for i := 0; i < 10 && !sr.State.Over; i++ {
+		if sr, err = c.Step(ctx, &snakepb.StepRequest{GameId: cr.GameId}); err != nil {
+			t.Fatalf("Could not step: %s", err)
+		}
+	}
+	if !sr.State.Over || len(sr.State.Results) != 1 || sr.State.Results[0].Reason != snakepb.EndReason_END_REASON_HIT_BOUNDARY {
+		t.Errorf("Game did not end at the boundary: %+v", sr.State)
+	}
+
+	ets := watchedTypes(t, w)
+	for _, et := range []snakepb.EventType{snakepb.EventType_EVENT_TYPE_TURNED, snakepb.EventType_EVENT_TYPE_TICKED, snakepb.EventType_EVENT_TYPE_MOVED, snakepb.EventType_EVENT_TYPE_DIED} {
+		if !watched(ets, et) {
+			t.Errorf("Watch did not get a %s event: %v", et, ets)
+		}
+	}
+
+	if _, err := c.Step(ctx, &snakepb.StepRequest{GameId: cr.GameId}); code(err) != codes.FailedPrecondition {
+		t.Errorf("Step after the game was over was not rejected: %s", err)
+	}
+	if gr, err := c.GetState(ctx, &snakepb.GetStateRequest{GameId: cr.GameId}); err != nil || !gr.State.Over {
+		t.Errorf("Could not get the final state: %v %s", gr, err)
+	}
+	if er, err := c.EndGame(ctx, &snakepb.EndGameRequest{GameId: cr.GameId}); err != nil || len(er.Results) != 1 {
+		t.Errorf("Could not end the game: %v %s", er, err)
+	}
+	if _, err := c.GetState(ctx, &snakepb.GetStateRequest{GameId: cr.GameId}); code(err) != codes.NotFound {
+		t.Errorf("Game was not forgotten when it ended: %s", err)
+	}
+}
+
+// Test that a game on the server clock plays by itself, and that Step pauses it
+func Test_ServiceClock(t *testing.T) {
+	ctx, c := testClient(t, rpc.Config{Clock: server.ClockRules{Period: 5 * time.Millisecond}})
+
+	cr, err := c.CreateGame(ctx, &snakepb.CreateGameRequest{Width: 40, Height: 40, Clock: true})
+	if err != nil {
+		t.Fatalf("Could not create a game: %s", err)
+	}
+	w := watch(t, ctx, c, cr.GameId)
+	for ticks := 0; ticks < 2; {
+		e, err := w.Recv()
+		if err != nil {
+			t.Fatalf("Watch failed: %s", err)
+		}
+		if e.Type == snakepb.EventType_EVENT_TYPE_TICKED {
+			ticks++
+		}
+	}
+
+	sr, err := c.Step(ctx, &snakepb.StepRequest{GameId: cr.GameId})
+	if err != nil {
+		t.Fatalf("Could not step: %s", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	gr, err := c.GetState(ctx, &snakepb.GetStateRequest{GameId: cr.GameId})
+	if err != nil || gr.State.Tick != sr.State.Tick {
+		t.Errorf("Game kept playing after a step: %d then %v %s", sr.State.Tick, gr, err)
+	}
+
+	if _, err := c.EndGame(ctx, &snakepb.EndGameRequest{GameId: cr.GameId}); err != nil {
+		t.Errorf("Could not end the game: %s", err)
+	}
+	if ets := watchedTypes(t, w); !watched(ets, snakepb.EventType_EVENT_TYPE_PAUSED) {
+		t.Errorf("Watch did not see the game pause: %v", ets)
+	}
+}
+
+// Test that a watch is kept behind the game, with a spectator delay
+func Test_ServiceWatchDelay(t *testing.T) {
+	ctx, c := testClient(t, rpc.Config{SpectatorDelay: 2})
+
+	cr, err := c.CreateGame(ctx, &snakepb.CreateGameRequest{Width: 20, Height: 20})
+	if err != nil {
+		t.Fatalf("Could not create a game: %s", err)
+	}
+	w := watch(t, ctx, c, cr.GameId)
+	es := make(chan *snakepb.Event, 64)
+	go func() {
+		for {
+			e, err := w.Recv()
+			if err != nil {
+				close(es)
+				return
+			}
+			es <- e
+		}
+	}()
+
+	step := func() {
+		if _, err := c.Step(ctx, &snakepb.StepRequest{GameId: cr.GameId}); err != nil {
+			t.Fatalf("Could not step: %s", err)
+		}
+	}
+	step()
+	step()
+	select {
+	case e := <-es:
+		t.Errorf("Watch got an event that isn't far enough behind: %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	step()
+	if e := <-es; e == nil || e.Type != snakepb.EventType_EVENT_TYPE_TICKED || e.Tick != 1 {
+		t.Errorf("Watch did not get the first tick once it was far enough behind: %+v", e)
+	}
+}
+
+// Test that bad requests are rejected
+func Test_ServiceErrors(t *testing.T) {
+	ctx, c := testClient(t, rpc.Config{})
+
+	for _, req := range []*snakepb.CreateGameRequest{
+		{Width: 1, Height: 10},
+		{Width: 100000, Height: 10},
+		{Height: 2147483647},
+		{FoodCount: 2147483647},
+		{FoodCount: -1},
+		{Snakes: 99},
+		{Level: "size 3 3\nmap\n"},
+	} {
+		if _, err := c.CreateGame(ctx, req); code(err) != codes.InvalidArgument {
+			t.Errorf("Bad game %+v was not rejected: %s", req, err)
+		}
+	}
+
+	cr, err := c.CreateGame(ctx, &snakepb.CreateGameRequest{Level: "size 4 4\nmap\n.....\n..F..\n.....\n..S..\n.....\n"})
+	if err != nil {
+		t.Fatalf("Could not create a game from a level: %s", err)
+	}
+	if h := cr.State.Snakes[0].Points[0]; h.X != 2 || h.Y != 1 {
+		t.Errorf("Level game has the snake in the wrong place: %+v", h)
+	}
+
+	if _, err := c.Turn(ctx, &snakepb.TurnRequest{GameId: cr.GameId}); code(err) != codes.InvalidArgument {
+		t.Errorf("Turn without a direction was not rejected: %s", err)
+	}
+	if _, err := c.Turn(ctx, &snakepb.TurnRequest{GameId: cr.GameId, Snake: 1, Dir: snakepb.Direction_DIRECTION_UP}); code(err) != codes.InvalidArgument {
+		t.Errorf("Turn for a missing snake was not rejected: %s", err)
+	}
+	for _, err := range []error{
+		func() error { _, err := c.Step(ctx, &snakepb.StepRequest{GameId: "7"}); return err }(),
+		func() error { _, err := c.GetState(ctx, &snakepb.GetStateRequest{GameId: "7"}); return err }(),
+		func() error { _, err := c.EndGame(ctx, &snakepb.EndGameRequest{GameId: "7"}); return err }(),
+		func() error { _, err := watch(t, ctx, c, "7").Recv(); return err }(),
+	} {
+		if code(err) != codes.NotFound {
+			t.Errorf("Missing game was not rejected: %s", err)
+		}
+	}
+}
+
+// Test that a service keeps at most MaxGames, and forgets finished games
+func Test_ServiceLimits(t *testing.T) {
+	ctx, c := testClient(t, rpc.Config{MaxGames: 2, KeepFinished: 20 * time.Millisecond})
+
+	cr, err := c.CreateGame(ctx, &snakepb.CreateGameRequest{Width: 4, Height: 4})
+	if err != nil {
+		t.Fatalf("Could not create a game: %s", err)
+	}
+	if _, err := c.CreateGame(ctx, &snakepb.CreateGameRequest{Width: 4, Height: 4}); err != nil {
+		t.Fatalf("Could not create a second game: %s", err)
+	}
+	if _, err := c.CreateGame(ctx, &snakepb.CreateGameRequest{Width: 4, Height: 4}); code(err) != codes.ResourceExhausted {
+		t.Errorf("Too many games were not rejected: %s", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if _, err := c.Step(ctx, &snakepb.StepRequest{GameId: cr.GameId}); err != nil {
+			break
+		}
+	}
+	if st, err := c.GetState(ctx, &snakepb.GetStateRequest{GameId: cr.GameId}); err != nil || len(st.State.Results) == 0 {
+		t.Fatalf("Finished game was not kept: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, err := c.GetState(ctx, &snakepb.GetStateRequest{GameId: cr.GameId}); code(err) != codes.NotFound {
+		t.Errorf("Finished game was not forgotten: %s", err)
+	}
+	if _, err := c.CreateGame(ctx, &snakepb.CreateGameRequest{Width: 4, Height: 4}); err != nil {
+		t.Errorf("Could not create a game once a finished game was forgotten: %s", err)
+	}
+}
@@ -0,0 +1,1577 @@
+// The snake game service: create games on a server, and control and watch them
+// remotely.
+//
+// Regenerate the Go code from the rpc directory with `go generate`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: snakepb/snake.proto
+
+package snakepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Direction int32
+
+const (
+	Direction_DIRECTION_UNSPECIFIED Direction = 0
+	Direction_DIRECTION_UP          Direction = 1
+	Direction_DIRECTION_DOWN        Direction = 2
+	Direction_DIRECTION_LEFT        Direction = 3
+	Direction_DIRECTION_RIGHT       Direction = 4
+)
+
+// Enum value maps for Direction.
+var (
+	Direction_name = map[int32]string{
+		0: "DIRECTION_UNSPECIFIED",
+		1: "DIRECTION_UP",
+		2: "DIRECTION_DOWN",
+		3: "DIRECTION_LEFT",
+		4: "DIRECTION_RIGHT",
+	}
+	Direction_value = map[string]int32{
+		"DIRECTION_UNSPECIFIED": 0,
+		"DIRECTION_UP":          1,
+		"DIRECTION_DOWN":        2,
+		"DIRECTION_LEFT":        3,
+		"DIRECTION_RIGHT":       4,
+	}
+)
+
+func (x Direction) Enum() *Direction {
+	p := new(Direction)
+	*p = x
+	return p
+}
+
+func (x Direction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Direction) Descriptor() protoreflect.EnumDescriptor {
+	return file_snakepb_snake_proto_enumTypes[0].Descriptor()
+}
+
+func (Direction) Type() protoreflect.EnumType {
+	return &file_snakepb_snake_proto_enumTypes[0]
+}
+
+func (x Direction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Direction.Descriptor instead.
+func (Direction) EnumDescriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{0}
+}
+
+// Same order as game.FoodKind
+type FoodKind int32
+
+const (
+	FoodKind_FOOD_KIND_NORMAL FoodKind = 0
+	FoodKind_FOOD_KIND_BONUS  FoodKind = 1
+	FoodKind_FOOD_KIND_GROW   FoodKind = 2
+	FoodKind_FOOD_KIND_SHRINK FoodKind = 3
+	FoodKind_FOOD_KIND_SPEED  FoodKind = 4
+)
+
+// Enum value maps for FoodKind.
+var (
+	FoodKind_name = map[int32]string{
+		0: "FOOD_KIND_NORMAL",
+		1: "FOOD_KIND_BONUS",
+		2: "FOOD_KIND_GROW",
+		3: "FOOD_KIND_SHRINK",
+		4: "FOOD_KIND_SPEED",
+	}
+	FoodKind_value = map[string]int32{
+		"FOOD_KIND_NORMAL": 0,
+		"FOOD_KIND_BONUS":  1,
+		"FOOD_KIND_GROW":   2,
+		"FOOD_KIND_SHRINK": 3,
+		"FOOD_KIND_SPEED":  4,
+	}
+)
+
+func (x FoodKind) Enum() *FoodKind {
+	p := new(FoodKind)
+	*p = x
+	return p
+}
+
+func (x FoodKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FoodKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_snakepb_snake_proto_enumTypes[1].Descriptor()
+}
+
+func (FoodKind) Type() protoreflect.EnumType {
+	return &file_snakepb_snake_proto_enumTypes[1]
+}
+
+func (x FoodKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FoodKind.Descriptor instead.
+func (FoodKind) EnumDescriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{1}
+}
+
+// Same order as game.EndReason
+type EndReason int32
+
+const (
+	EndReason_END_REASON_PLAYING      EndReason = 0
+	EndReason_END_REASON_VICTORY      EndReason = 1
+	EndReason_END_REASON_HIT_BOUNDARY EndReason = 2
+	EndReason_END_REASON_HIT_OBSTACLE EndReason = 3
+	EndReason_END_REASON_HIT_SNAKE    EndReason = 4
+	EndReason_END_REASON_HIT_HEAD     EndReason = 5
+)
+
+// Enum value maps for EndReason.
+var (
+	EndReason_name = map[int32]string{
+		0: "END_REASON_PLAYING",
+		1: "END_REASON_VICTORY",
+		2: "END_REASON_HIT_BOUNDARY",
+		3: "END_REASON_HIT_OBSTACLE",
+		4: "END_REASON_HIT_SNAKE",
+		5: "END_REASON_HIT_HEAD",
+	}
+	EndReason_value = map[string]int32{
+		"END_REASON_PLAYING":      0,
+		"END_REASON_VICTORY":      1,
+		"END_REASON_HIT_BOUNDARY": 2,
+		"END_REASON_HIT_OBSTACLE": 3,
+		"END_REASON_HIT_SNAKE":    4,
+		"END_REASON_HIT_HEAD":     5,
+	}
+)
+
+func (x EndReason) Enum() *EndReason {
+	p := new(EndReason)
+	*p = x
+	return p
+}
+
+func (x EndReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EndReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_snakepb_snake_proto_enumTypes[2].Descriptor()
+}
+
+func (EndReason) Type() protoreflect.EnumType {
+	return &file_snakepb_snake_proto_enumTypes[2]
+}
+
+func (x EndReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EndReason.Descriptor instead.
+func (EndReason) EnumDescriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{2}
+}
+
+// The server.EventType order, after UNSPECIFIED
+type EventType int32
+
+const (
+	EventType_EVENT_TYPE_UNSPECIFIED   EventType = 0
+	EventType_EVENT_TYPE_TICKED        EventType = 1
+	EventType_EVENT_TYPE_MOVED         EventType = 2
+	EventType_EVENT_TYPE_GREW          EventType = 3
+	EventType_EVENT_TYPE_ATE           EventType = 4
+	EventType_EVENT_TYPE_TURNED        EventType = 5
+	EventType_EVENT_TYPE_TURN_REJECTED EventType = 6
+	EventType_EVENT_TYPE_FOOD_PLACED   EventType = 7
+	EventType_EVENT_TYPE_FOOD_REJECTED EventType = 8
+	EventType_EVENT_TYPE_DIED          EventType = 9
+	EventType_EVENT_TYPE_WON           EventType = 10
+	EventType_EVENT_TYPE_PAUSED        EventType = 11
+	EventType_EVENT_TYPE_RESUMED       EventType = 12
+	EventType_EVENT_TYPE_SPEED         EventType = 13
+	EventType_EVENT_TYPE_CLOCK         EventType = 14
+)
+
+// Enum value maps for EventType.
+var (
+	EventType_name = map[int32]string{
+		0:  "EVENT_TYPE_UNSPECIFIED",
+		1:  "EVENT_TYPE_TICKED",
+		2:  "EVENT_TYPE_MOVED",
+		3:  "EVENT_TYPE_GREW",
+		4:  "EVENT_TYPE_ATE",
+		5:  "EVENT_TYPE_TURNED",
+		6:  "EVENT_TYPE_TURN_REJECTED",
+		7:  "EVENT_TYPE_FOOD_PLACED",
+		8:  "EVENT_TYPE_FOOD_REJECTED",
+		9:  "EVENT_TYPE_DIED",
+		10: "EVENT_TYPE_WON",
+		11: "EVENT_TYPE_PAUSED",
+		12: "EVENT_TYPE_RESUMED",
+		13: "EVENT_TYPE_SPEED",
+		14: "EVENT_TYPE_CLOCK",
+	}
+	EventType_value = map[string]int32{
+		"EVENT_TYPE_UNSPECIFIED":   0,
+		"EVENT_TYPE_TICKED":        1,
+		"EVENT_TYPE_MOVED":         2,
+		"EVENT_TYPE_GREW":          3,
+		"EVENT_TYPE_ATE":           4,
+		"EVENT_TYPE_TURNED":        5,
+		"EVENT_TYPE_TURN_REJECTED": 6,
+		"EVENT_TYPE_FOOD_PLACED":   7,
+		"EVENT_TYPE_FOOD_REJECTED": 8,
+		"EVENT_TYPE_DIED":          9,
+		"EVENT_TYPE_WON":           10,
+		"EVENT_TYPE_PAUSED":        11,
+		"EVENT_TYPE_RESUMED":       12,
+		"EVENT_TYPE_SPEED":         13,
+		"EVENT_TYPE_CLOCK":         14,
+	}
+)
+
+func (x EventType) Enum() *EventType {
+	p := new(EventType)
+	*p = x
+	return p
+}
+
+func (x EventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_snakepb_snake_proto_enumTypes[3].Descriptor()
+}
+
+func (EventType) Type() protoreflect.EnumType {
+	return &file_snakepb_snake_proto_enumTypes[3]
+}
+
+func (x EventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventType.Descriptor instead.
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{3}
+}
+
+// A grid point (Up is +Y)
+type Point struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X             int32                  `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y             int32                  `protobuf:"varint,2,opt,name=y,proto3" json:"y,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Point) Reset() {
+	*x = Point{}
+	mi := &file_snakepb_snake_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Point) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Point) ProtoMessage() {}
+
+func (x *Point) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Point.ProtoReflect.Descriptor instead.
+func (*Point) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Point) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Point) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+type Food struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Point         *Point                 `protobuf:"bytes,1,opt,name=point,proto3" json:"point,omitempty"`
+	Kind          FoodKind               `protobuf:"varint,2,opt,name=kind,proto3,enum=snake.v1.FoodKind" json:"kind,omitempty"`
+	Value         int32                  `protobuf:"varint,3,opt,name=value,proto3" json:"value,omitempty"`     // how much of its effect the food has, for kinds that need one
+	Expires       uint32                 `protobuf:"varint,4,opt,name=expires,proto3" json:"expires,omitempty"` // the last tick that the food can be eaten on, 0 if it never expires
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Food) Reset() {
+	*x = Food{}
+	mi := &file_snakepb_snake_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Food) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Food) ProtoMessage() {}
+
+func (x *Food) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Food.ProtoReflect.Descriptor instead.
+func (*Food) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Food) GetPoint() *Point {
+	if x != nil {
+		return x.Point
+	}
+	return nil
+}
+
+func (x *Food) GetKind() FoodKind {
+	if x != nil {
+		return x.Kind
+	}
+	return FoodKind_FOOD_KIND_NORMAL
+}
+
+func (x *Food) GetValue() int32 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Food) GetExpires() uint32 {
+	if x != nil {
+		return x.Expires
+	}
+	return 0
+}
+
+type Snake struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Points        []*Point               `protobuf:"bytes,2,rep,name=points,proto3" json:"points,omitempty"` // from head to tail
+	Facing        Direction              `protobuf:"varint,3,opt,name=facing,proto3,enum=snake.v1.Direction" json:"facing,omitempty"`
+	Alive         bool                   `protobuf:"varint,4,opt,name=alive,proto3" json:"alive,omitempty"`
+	Score         int32                  `protobuf:"varint,5,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Snake) Reset() {
+	*x = Snake{}
+	mi := &file_snakepb_snake_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snake) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snake) ProtoMessage() {}
+
+func (x *Snake) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snake.ProtoReflect.Descriptor instead.
+func (*Snake) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Snake) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Snake) GetPoints() []*Point {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+func (x *Snake) GetFacing() Direction {
+	if x != nil {
+		return x.Facing
+	}
+	return Direction_DIRECTION_UNSPECIFIED
+}
+
+func (x *Snake) GetAlive() bool {
+	if x != nil {
+		return x.Alive
+	}
+	return false
+}
+
+func (x *Snake) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type Result struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Snake         int32                  `protobuf:"varint,1,opt,name=snake,proto3" json:"snake,omitempty"`
+	Reason        EndReason              `protobuf:"varint,2,opt,name=reason,proto3,enum=snake.v1.EndReason" json:"reason,omitempty"`
+	Length        uint32                 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	Ticks         uint32                 `protobuf:"varint,4,opt,name=ticks,proto3" json:"ticks,omitempty"`
+	FoodEaten     uint32                 `protobuf:"varint,5,opt,name=food_eaten,json=foodEaten,proto3" json:"food_eaten,omitempty"`
+	Score         int32                  `protobuf:"varint,6,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_snakepb_snake_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Result) GetSnake() int32 {
+	if x != nil {
+		return x.Snake
+	}
+	return 0
+}
+
+func (x *Result) GetReason() EndReason {
+	if x != nil {
+		return x.Reason
+	}
+	return EndReason_END_REASON_PLAYING
+}
+
+func (x *Result) GetLength() uint32 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *Result) GetTicks() uint32 {
+	if x != nil {
+		return x.Ticks
+	}
+	return 0
+}
+
+func (x *Result) GetFoodEaten() uint32 {
+	if x != nil {
+		return x.FoodEaten
+	}
+	return 0
+}
+
+func (x *Result) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+// A whole game frame
+type GameState struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameId        string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	Tick          uint32                 `protobuf:"varint,2,opt,name=tick,proto3" json:"tick,omitempty"`
+	Size          *Point                 `protobuf:"bytes,3,opt,name=size,proto3" json:"size,omitempty"` // the largest X and Y on the grid
+	Walls         []*Point               `protobuf:"bytes,4,rep,name=walls,proto3" json:"walls,omitempty"`
+	Snakes        []*Snake               `protobuf:"bytes,5,rep,name=snakes,proto3" json:"snakes,omitempty"`
+	Foods         []*Food                `protobuf:"bytes,6,rep,name=foods,proto3" json:"foods,omitempty"`
+	Speed         int32                  `protobuf:"varint,7,opt,name=speed,proto3" json:"speed,omitempty"`
+	Over          bool                   `protobuf:"varint,8,opt,name=over,proto3" json:"over,omitempty"`
+	Results       []*Result              `protobuf:"bytes,9,rep,name=results,proto3" json:"results,omitempty"` // once the game is over
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GameState) Reset() {
+	*x = GameState{}
+	mi := &file_snakepb_snake_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameState) ProtoMessage() {}
+
+func (x *GameState) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameState.ProtoReflect.Descriptor instead.
+func (*GameState) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GameState) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *GameState) GetTick() uint32 {
+	if x != nil {
+		return x.Tick
+	}
+	return 0
+}
+
+func (x *GameState) GetSize() *Point {
+	if x != nil {
+		return x.Size
+	}
+	return nil
+}
+
+func (x *GameState) GetWalls() []*Point {
+	if x != nil {
+		return x.Walls
+	}
+	return nil
+}
+
+func (x *GameState) GetSnakes() []*Snake {
+	if x != nil {
+		return x.Snakes
+	}
+	return nil
+}
+
+func (x *GameState) GetFoods() []*Food {
+	if x != nil {
+		return x.Foods
+	}
+	return nil
+}
+
+func (x *GameState) GetSpeed() int32 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *GameState) GetOver() bool {
+	if x != nil {
+		return x.Over
+	}
+	return false
+}
+
+func (x *GameState) GetResults() []*Result {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// A server event
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Tick          uint32                 `protobuf:"varint,2,opt,name=tick,proto3" json:"tick,omitempty"`
+	Type          EventType              `protobuf:"varint,3,opt,name=type,proto3,enum=snake.v1.EventType" json:"type,omitempty"`
+	Snake         int32                  `protobuf:"varint,4,opt,name=snake,proto3" json:"snake,omitempty"`
+	Point         *Point                 `protobuf:"bytes,5,opt,name=point,proto3" json:"point,omitempty"`                               // the snake head, for snake events
+	Dir           Direction              `protobuf:"varint,6,opt,name=dir,proto3,enum=snake.v1.Direction" json:"dir,omitempty"`          // the direction, for turns
+	Food          *Food                  `protobuf:"bytes,7,opt,name=food,proto3" json:"food,omitempty"`                                 // the food, for food events
+	Points        int32                  `protobuf:"varint,8,opt,name=points,proto3" json:"points,omitempty"`                            // the points scored, for moves
+	Reason        EndReason              `protobuf:"varint,9,opt,name=reason,proto3,enum=snake.v1.EndReason" json:"reason,omitempty"`    // how the snake's game ended, for deaths and wins
+	Speed         int32                  `protobuf:"varint,10,opt,name=speed,proto3" json:"speed,omitempty"`                             // the game speed level, for speed changes
+	IntervalMs    int64                  `protobuf:"varint,11,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"` // the clock period, for clock changes
+	Error         string                 `protobuf:"bytes,12,opt,name=error,proto3" json:"error,omitempty"`                              // what went wrong, for deaths and rejections
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_snakepb_snake_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Event) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Event) GetTick() uint32 {
+	if x != nil {
+		return x.Tick
+	}
+	return 0
+}
+
+func (x *Event) GetType() EventType {
+	if x != nil {
+		return x.Type
+	}
+	return EventType_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *Event) GetSnake() int32 {
+	if x != nil {
+		return x.Snake
+	}
+	return 0
+}
+
+func (x *Event) GetPoint() *Point {
+	if x != nil {
+		return x.Point
+	}
+	return nil
+}
+
+func (x *Event) GetDir() Direction {
+	if x != nil {
+		return x.Dir
+	}
+	return Direction_DIRECTION_UNSPECIFIED
+}
+
+func (x *Event) GetFood() *Food {
+	if x != nil {
+		return x.Food
+	}
+	return nil
+}
+
+func (x *Event) GetPoints() int32 {
+	if x != nil {
+		return x.Points
+	}
+	return 0
+}
+
+func (x *Event) GetReason() EndReason {
+	if x != nil {
+		return x.Reason
+	}
+	return EndReason_END_REASON_PLAYING
+}
+
+func (x *Event) GetSpeed() int32 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *Event) GetIntervalMs() int64 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+func (x *Event) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type CreateGameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Width         int32                  `protobuf:"varint,1,opt,name=width,proto3" json:"width,omitempty"`                          // the grid width (20 if it isn't set, at most 200)
+	Height        int32                  `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`                        // the grid height (20 if it isn't set, at most 200)
+	Snakes        int32                  `protobuf:"varint,3,opt,name=snakes,proto3" json:"snakes,omitempty"`                        // how many snakes (1 if it isn't set, at most 8)
+	FoodCount     int32                  `protobuf:"varint,4,opt,name=food_count,json=foodCount,proto3" json:"food_count,omitempty"` // how much food to keep on the board (at most 100)
+	Level         string                 `protobuf:"bytes,5,opt,name=level,proto3" json:"level,omitempty"`                           // (optional) a level, as level file text, instead of an empty grid
+	Clock         bool                   `protobuf:"varint,6,opt,name=clock,proto3" json:"clock,omitempty"`                          // run the game on the server clock, instead of only on Step
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateGameRequest) Reset() {
+	*x = CreateGameRequest{}
+	mi := &file_snakepb_snake_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGameRequest) ProtoMessage() {}
+
+func (x *CreateGameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGameRequest.ProtoReflect.Descriptor instead.
+func (*CreateGameRequest) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateGameRequest) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *CreateGameRequest) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *CreateGameRequest) GetSnakes() int32 {
+	if x != nil {
+		return x.Snakes
+	}
+	return 0
+}
+
+func (x *CreateGameRequest) GetFoodCount() int32 {
+	if x != nil {
+		return x.FoodCount
+	}
+	return 0
+}
+
+func (x *CreateGameRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *CreateGameRequest) GetClock() bool {
+	if x != nil {
+		return x.Clock
+	}
+	return false
+}
+
+type CreateGameResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameId        string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	State         *GameState             `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateGameResponse) Reset() {
+	*x = CreateGameResponse{}
+	mi := &file_snakepb_snake_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGameResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGameResponse) ProtoMessage() {}
+
+func (x *CreateGameResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGameResponse.ProtoReflect.Descriptor instead.
+func (*CreateGameResponse) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreateGameResponse) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *CreateGameResponse) GetState() *GameState {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+type TurnRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameId        string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	Snake         int32                  `protobuf:"varint,2,opt,name=snake,proto3" json:"snake,omitempty"`
+	Dir           Direction              `protobuf:"varint,3,opt,name=dir,proto3,enum=snake.v1.Direction" json:"dir,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TurnRequest) Reset() {
+	*x = TurnRequest{}
+	mi := &file_snakepb_snake_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TurnRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TurnRequest) ProtoMessage() {}
+
+func (x *TurnRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TurnRequest.ProtoReflect.Descriptor instead.
+func (*TurnRequest) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TurnRequest) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *TurnRequest) GetSnake() int32 {
+	if x != nil {
+		return x.Snake
+	}
+	return 0
+}
+
+func (x *TurnRequest) GetDir() Direction {
+	if x != nil {
+		return x.Dir
+	}
+	return Direction_DIRECTION_UNSPECIFIED
+}
+
+// Turns are checked as the game plays them: a rejected turn shows up as a
+// TURN_REJECTED event
+type TurnResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TurnResponse) Reset() {
+	*x = TurnResponse{}
+	mi := &file_snakepb_snake_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TurnResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TurnResponse) ProtoMessage() {}
+
+func (x *TurnResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TurnResponse.ProtoReflect.Descriptor instead.
+func (*TurnResponse) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{9}
+}
+
+type StepRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameId        string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StepRequest) Reset() {
+	*x = StepRequest{}
+	mi := &file_snakepb_snake_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepRequest) ProtoMessage() {}
+
+func (x *StepRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepRequest.ProtoReflect.Descriptor instead.
+func (*StepRequest) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StepRequest) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+type StepResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         *GameState             `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StepResponse) Reset() {
+	*x = StepResponse{}
+	mi := &file_snakepb_snake_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepResponse) ProtoMessage() {}
+
+func (x *StepResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepResponse.ProtoReflect.Descriptor instead.
+func (*StepResponse) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *StepResponse) GetState() *GameState {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+type GetStateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameId        string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStateRequest) Reset() {
+	*x = GetStateRequest{}
+	mi := &file_snakepb_snake_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStateRequest) ProtoMessage() {}
+
+func (x *GetStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStateRequest.ProtoReflect.Descriptor instead.
+func (*GetStateRequest) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetStateRequest) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+type GetStateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         *GameState             `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStateResponse) Reset() {
+	*x = GetStateResponse{}
+	mi := &file_snakepb_snake_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStateResponse) ProtoMessage() {}
+
+func (x *GetStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStateResponse.ProtoReflect.Descriptor instead.
+func (*GetStateResponse) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetStateResponse) GetState() *GameState {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+type WatchEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameId        string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_snakepb_snake_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *WatchEventsRequest) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+type EndGameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameId        string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EndGameRequest) Reset() {
+	*x = EndGameRequest{}
+	mi := &file_snakepb_snake_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EndGameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndGameRequest) ProtoMessage() {}
+
+func (x *EndGameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndGameRequest.ProtoReflect.Descriptor instead.
+func (*EndGameRequest) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *EndGameRequest) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+type EndGameResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*Result              `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EndGameResponse) Reset() {
+	*x = EndGameResponse{}
+	mi := &file_snakepb_snake_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EndGameResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndGameResponse) ProtoMessage() {}
+
+func (x *EndGameResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_snakepb_snake_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndGameResponse.ProtoReflect.Descriptor instead.
+func (*EndGameResponse) Descriptor() ([]byte, []int) {
+	return file_snakepb_snake_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *EndGameResponse) GetResults() []*Result {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+var File_snakepb_snake_proto protoreflect.FileDescriptor
+
+const file_snakepb_snake_proto_rawDesc = "" +
+	"\n" +
+	"\x13snakepb/snake.proto\x12\bsnake.v1\"#\n" +
+	"\x05Point\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x05R\x01x\x12\f\n" +
+	"\x01y\x18\x02 \x01(\x05R\x01y\"\x85\x01\n" +
+	"\x04Food\x12%\n" +
+	"\x05point\x18\x01 \x01(\v2\x0f.snake.v1.PointR\x05point\x12&\n" +
+	"\x04kind\x18\x02 \x01(\x0e2\x12.snake.v1.FoodKindR\x04kind\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\x05R\x05value\x12\x18\n" +
+	"\aexpires\x18\x04 \x01(\rR\aexpires\"\x99\x01\n" +
+	"\x05Snake\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12'\n" +
+	"\x06points\x18\x02 \x03(\v2\x0f.snake.v1.PointR\x06points\x12+\n" +
+	"\x06facing\x18\x03 \x01(\x0e2\x13.snake.v1.DirectionR\x06facing\x12\x14\n" +
+	"\x05alive\x18\x04 \x01(\bR\x05alive\x12\x14\n" +
+	"\x05score\x18\x05 \x01(\x05R\x05score\"\xae\x01\n" +
+	"\x06Result\x12\x14\n" +
+	"\x05snake\x18\x01 \x01(\x05R\x05snake\x12+\n" +
+	"\x06reason\x18\x02 \x01(\x0e2\x13.snake.v1.EndReasonR\x06reason\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\rR\x06length\x12\x14\n" +
+	"\x05ticks\x18\x04 \x01(\rR\x05ticks\x12\x1d\n" +
+	"\n" +
+	"food_eaten\x18\x05 \x01(\rR\tfoodEaten\x12\x14\n" +
+	"\x05score\x18\x06 \x01(\x05R\x05score\"\xa9\x02\n" +
+	"\tGameState\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\x12\x12\n" +
+	"\x04tick\x18\x02 \x01(\rR\x04tick\x12#\n" +
+	"\x04size\x18\x03 \x01(\v2\x0f.snake.v1.PointR\x04size\x12%\n" +
+	"\x05walls\x18\x04 \x03(\v2\x0f.snake.v1.PointR\x05walls\x12'\n" +
+	"\x06snakes\x18\x05 \x03(\v2\x0f.snake.v1.SnakeR\x06snakes\x12$\n" +
+	"\x05foods\x18\x06 \x03(\v2\x0e.snake.v1.FoodR\x05foods\x12\x14\n" +
+	"\x05speed\x18\a \x01(\x05R\x05speed\x12\x12\n" +
+	"\x04over\x18\b \x01(\bR\x04over\x12*\n" +
+	"\aresults\x18\t \x03(\v2\x10.snake.v1.ResultR\aresults\"\xf0\x02\n" +
+	"\x05Event\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12\x12\n" +
+	"\x04tick\x18\x02 \x01(\rR\x04tick\x12'\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x13.snake.v1.EventTypeR\x04type\x12\x14\n" +
+	"\x05snake\x18\x04 \x01(\x05R\x05snake\x12%\n" +
+	"\x05point\x18\x05 \x01(\v2\x0f.snake.v1.PointR\x05point\x12%\n" +
+	"\x03dir\x18\x06 \x01(\x0e2\x13.snake.v1.DirectionR\x03dir\x12\"\n" +
+	"\x04food\x18\a \x01(\v2\x0e.snake.v1.FoodR\x04food\x12\x16\n" +
+	"\x06points\x18\b \x01(\x05R\x06points\x12+\n" +
+	"\x06reason\x18\t \x01(\x0e2\x13.snake.v1.EndReasonR\x06reason\x12\x14\n" +
+	"\x05speed\x18\n" +
+	" \x01(\x05R\x05speed\x12\x1f\n" +
+	"\vinterval_ms\x18\v \x01(\x03R\n" +
+	"intervalMs\x12\x14\n" +
+	"\x05error\x18\f \x01(\tR\x05error\"\xa4\x01\n" +
+	"\x11CreateGameRequest\x12\x14\n" +
+	"\x05width\x18\x01 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x02 \x01(\x05R\x06height\x12\x16\n" +
+	"\x06snakes\x18\x03 \x01(\x05R\x06snakes\x12\x1d\n" +
+	"\n" +
+	"food_count\x18\x04 \x01(\x05R\tfoodCount\x12\x14\n" +
+	"\x05level\x18\x05 \x01(\tR\x05level\x12\x14\n" +
+	"\x05clock\x18\x06 \x01(\bR\x05clock\"X\n" +
+	"\x12CreateGameResponse\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\x12)\n" +
+	"\x05state\x18\x02 \x01(\v2\x13.snake.v1.GameStateR\x05state\"c\n" +
+	"\vTurnRequest\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\x12\x14\n" +
+	"\x05snake\x18\x02 \x01(\x05R\x05snake\x12%\n" +
+	"\x03dir\x18\x03 \x01(\x0e2\x13.snake.v1.DirectionR\x03dir\"\x0e\n" +
+	"\fTurnResponse\"&\n" +
+	"\vStepRequest\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\"9\n" +
+	"\fStepResponse\x12)\n" +
+	"\x05state\x18\x01 \x01(\v2\x13.snake.v1.GameStateR\x05state\"*\n" +
+	"\x0fGetStateRequest\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\"=\n" +
+	"\x10GetStateResponse\x12)\n" +
+	"\x05state\x18\x01 \x01(\v2\x13.snake.v1.GameStateR\x05state\"-\n" +
+	"\x12WatchEventsRequest\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\")\n" +
+	"\x0eEndGameRequest\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\"=\n" +
+	"\x0fEndGameResponse\x12*\n" +
+	"\aresults\x18\x01 \x03(\v2\x10.snake.v1.ResultR\aresults*u\n" +
+	"\tDirection\x12\x19\n" +
+	"\x15DIRECTION_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fDIRECTION_UP\x10\x01\x12\x12\n" +
+	"\x0eDIRECTION_DOWN\x10\x02\x12\x12\n" +
+	"\x0eDIRECTION_LEFT\x10\x03\x12\x13\n" +
+	"\x0fDIRECTION_RIGHT\x10\x04*t\n" +
+	"\bFoodKind\x12\x14\n" +
+	"\x10FOOD_KIND_NORMAL\x10\x00\x12\x13\n" +
+	"\x0fFOOD_KIND_BONUS\x10\x01\x12\x12\n" +
+	"\x0eFOOD_KIND_GROW\x10\x02\x12\x14\n" +
+	"\x10FOOD_KIND_SHRINK\x10\x03\x12\x13\n" +
+	"\x0fFOOD_KIND_SPEED\x10\x04*\xa8\x01\n" +
+	"\tEndReason\x12\x16\n" +
+	"\x12END_REASON_PLAYING\x10\x00\x12\x16\n" +
+	"\x12END_REASON_VICTORY\x10\x01\x12\x1b\n" +
+	"\x17END_REASON_HIT_BOUNDARY\x10\x02\x12\x1b\n" +
+	"\x17END_REASON_HIT_OBSTACLE\x10\x03\x12\x18\n" +
+	"\x14END_REASON_HIT_SNAKE\x10\x04\x12\x17\n" +
+	"\x13END_REASON_HIT_HEAD\x10\x05*\xf0\x02\n" +
+	"\tEventType\x12\x1a\n" +
+	"\x16EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11EVENT_TYPE_TICKED\x10\x01\x12\x14\n" +
+	"\x10EVENT_TYPE_MOVED\x10\x02\x12\x13\n" +
+	"\x0fEVENT_TYPE_GREW\x10\x03\x12\x12\n" +
+	"\x0eEVENT_TYPE_ATE\x10\x04\x12\x15\n" +
+	"\x11EVENT_TYPE_TURNED\x10\x05\x12\x1c\n" +
+	"\x18EVENT_TYPE_TURN_REJECTED\x10\x06\x12\x1a\n" +
+	"\x16EVENT_TYPE_FOOD_PLACED\x10\a\x12\x1c\n" +
+	"\x18EVENT_TYPE_FOOD_REJECTED\x10\b\x12\x13\n" +
+	"\x0fEVENT_TYPE_DIED\x10\t\x12\x12\n" +
+	"\x0eEVENT_TYPE_WON\x10\n" +
+	"\x12\x15\n" +
+	"\x11EVENT_TYPE_PAUSED\x10\v\x12\x16\n" +
+	"\x12EVENT_TYPE_RESUMED\x10\f\x12\x14\n" +
+	"\x10EVENT_TYPE_SPEED\x10\r\x12\x14\n" +
+	"\x10EVENT_TYPE_CLOCK\x10\x0e2\x88\x03\n" +
+	"\fSnakeService\x12G\n" +
+	"\n" +
+	"CreateGame\x12\x1b.snake.v1.CreateGameRequest\x1a\x1c.snake.v1.CreateGameResponse\x125\n" +
+	"\x04Turn\x12\x15.snake.v1.TurnRequest\x1a\x16.snake.v1.TurnResponse\x125\n" +
+	"\x04Step\x12\x15.snake.v1.StepRequest\x1a\x16.snake.v1.StepResponse\x12A\n" +
+	"\bGetState\x12\x19.snake.v1.GetStateRequest\x1a\x1a.snake.v1.GetStateResponse\x12>\n" +
+	"\vWatchEvents\x12\x1c.snake.v1.WatchEventsRequest\x1a\x0f.snake.v1.Event0\x01\x12>\n" +
+	"\aEndGame\x12\x18.snake.v1.EndGameRequest\x1a\x19.snake.v1.EndGameResponseB,Z*github.com/james-nesbitt/snake/rpc/snakepbb\x06proto3"
+
+var (
+	file_snakepb_snake_proto_rawDescOnce sync.Once
+	file_snakepb_snake_proto_rawDescData []byte
+)
+
+func file_snakepb_snake_proto_rawDescGZIP() []byte {
+	file_snakepb_snake_proto_rawDescOnce.Do(func() {
+		file_snakepb_snake_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_snakepb_snake_proto_rawDesc), len(file_snakepb_snake_proto_rawDesc)))
+	})
+	return file_snakepb_snake_proto_rawDescData
+}
+
+var file_snakepb_snake_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_snakepb_snake_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_snakepb_snake_proto_goTypes = []any{
+	(Direction)(0),             // 0: snake.v1.Direction
+	(FoodKind)(0),              // 1: snake.v1.FoodKind
+	(EndReason)(0),             // 2: snake.v1.EndReason
+	(EventType)(0),             // 3: snake.v1.EventType
+	(*Point)(nil),              // 4: snake.v1.Point
+	(*Food)(nil),               // 5: snake.v1.Food
+	(*Snake)(nil),              // 6: snake.v1.Snake
+	(*Result)(nil),             // 7: snake.v1.Result
+	(*GameState)(nil),          // 8: snake.v1.GameState
+	(*Event)(nil),              // 9: snake.v1.Event
+	(*CreateGameRequest)(nil),  // 10: snake.v1.CreateGameRequest
+	(*CreateGameResponse)(nil), // 11: snake.v1.CreateGameResponse
+	(*TurnRequest)(nil),        // 12: snake.v1.TurnRequest
+	(*TurnResponse)(nil),       // 13: snake.v1.TurnResponse
+	(*StepRequest)(nil),        // 14: snake.v1.StepRequest
+	(*StepResponse)(nil),       // 15: snake.v1.StepResponse
+	(*GetStateRequest)(nil),    // 16: snake.v1.GetStateRequest
+	(*GetStateResponse)(nil),   // 17: snake.v1.GetStateResponse
+	(*WatchEventsRequest)(nil), // 18: snake.v1.WatchEventsRequest
+	(*EndGameRequest)(nil),     // 19: snake.v1.EndGameRequest
+	(*EndGameResponse)(nil),    // 20: snake.v1.EndGameResponse
+}
+var file_snakepb_snake_proto_depIdxs = []int32{
+	4,  // 0: snake.v1.Food.point:type_name -> snake.v1.Point
+	1,  // 1: snake.v1.Food.kind:type_name -> snake.v1.FoodKind
+	4,  // 2: snake.v1.Snake.points:type_name -> snake.v1.Point
+	0,  // 3: snake.v1.Snake.facing:type_name -> snake.v1.Direction
+	2,  // 4: snake.v1.Result.reason:type_name -> snake.v1.EndReason
+	4,  // 5: snake.v1.GameState.size:type_name -> snake.v1.Point
+	4,  // 6: snake.v1.GameState.walls:type_name -> snake.v1.Point
+	6,  // 7: snake.v1.GameState.snakes:type_name -> snake.v1.Snake
+	5,  // 8: snake.v1.GameState.foods:type_name -> snake.v1.Food
+	7,  // 9: snake.v1.GameState.results:type_name -> snake.v1.Result
+	3,  // 10: snake.v1.Event.type:type_name -> snake.v1.EventType
+	4,  // 11: snake.v1.Event.point:type_name -> snake.v1.Point
+	0,  // 12: snake.v1.Event.dir:type_name -> snake.v1.Direction
+	5,  // 13: snake.v1.Event.food:type_name -> snake.v1.Food
+	2,  // 14: snake.v1.Event.reason:type_name -> snake.v1.EndReason
+	8,  // 15: snake.v1.CreateGameResponse.state:type_name -> snake.v1.GameState
+	0,  // 16: snake.v1.TurnRequest.dir:type_name -> snake.v1.Direction
+	8,  // 17: snake.v1.StepResponse.state:type_name -> snake.v1.GameState
+	8,  // 18: snake.v1.GetStateResponse.state:type_name -> snake.v1.GameState
+	7,  // 19: snake.v1.EndGameResponse.results:type_name -> snake.v1.Result
+	10, // 20: snake.v1.SnakeService.CreateGame:input_type -> snake.v1.CreateGameRequest
+	12, // 21: snake.v1.SnakeService.Turn:input_type -> snake.v1.TurnRequest
+	14, // 22: snake.v1.SnakeService.Step:input_type -> snake.v1.StepRequest
+	16, // 23: snake.v1.SnakeService.GetState:input_type -> snake.v1.GetStateRequest
+	18, // 24: snake.v1.SnakeService.WatchEvents:input_type -> snake.v1.WatchEventsRequest
+	19, // 25: snake.v1.SnakeService.EndGame:input_type -> snake.v1.EndGameRequest
+	11, // 26: snake.v1.SnakeService.CreateGame:output_type -> snake.v1.CreateGameResponse
+	13, // 27: snake.v1.SnakeService.Turn:output_type -> snake.v1.TurnResponse
+	15, // 28: snake.v1.SnakeService.Step:output_type -> snake.v1.StepResponse
+	17, // 29: snake.v1.SnakeService.GetState:output_type -> snake.v1.GetStateResponse
+	9,  // 30: snake.v1.SnakeService.WatchEvents:output_type -> snake.v1.Event
+	20, // 31: snake.v1.SnakeService.EndGame:output_type -> snake.v1.EndGameResponse
+	26, // [26:32] is the sub-list for method output_type
+	20, // [20:26] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_snakepb_snake_proto_init() }
+func file_snakepb_snake_proto_init() {
+	if File_snakepb_snake_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_snakepb_snake_proto_rawDesc), len(file_snakepb_snake_proto_rawDesc)),
+			NumEnums:      4,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_snakepb_snake_proto_goTypes,
+		DependencyIndexes: file_snakepb_snake_proto_depIdxs,
+		EnumInfos:         file_snakepb_snake_proto_enumTypes,
+		MessageInfos:      file_snakepb_snake_proto_msgTypes,
+	}.Build()
+	File_snakepb_snake_proto = out.File
+	file_snakepb_snake_proto_goTypes = nil
+	file_snakepb_snake_proto_depIdxs = nil
+}
@@ -105,16 +105,14 @@ func draw(g *gocui.Gui) error {
 func updateHistory() {
 	hv.Clear()
 	for i, s := range h {
-		fmt.Fprintln(hv, "%3d) %s", i, s)
+		fmt.Fprintf(hv, "%3d) %s\n", i, s)
 	}
 }
 func updateGrid() {
 	gv.Clear()
 
-	if f, err := s.Game.Food(); err != nil {
-		log.Printf("%s", err)
-	} else {
-		gv.SetCursor(f.X, f.Y)
+	for _, f := range s.Game.Foods() {
+		gv.SetCursor(f.Point.X, f.Point.Y)
 		gv.Write([]byte("F"))
 	}
 
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"github.com/james-nesbitt/snake/game"
+	"log"
+	"time"
+)
+
+/**
+ * The server can run its own clock, instead of waiting for Tick commands.  A
+ * self-clocked server ticks the game every period, where the period comes from
+ * the game speed level and the server ClockRules:
+ *  1. level 0 ticks every Period, and every level up takes Step off of that,
+ *     down to the Min period (levels below 0 slow down, up to any Max)
+ *  2. eating FoodPerLevel food (between all of the snakes) speeds the game up a
+ *     level, and speed food changes the level in the game itself.  Eating only
+ *     speeds up a game once the clock has been on, or if ClockRules were set, so
+ *     that a game ticked by Tick commands keeps its speed
+ *
+ * The clock can be switched on and off at any time with SwitchClock, and Tick
+ * commands are still accepted while it runs.  Whenever the period changes it
+ * goes out as a clock event (0 when the clock is switched off), so that UIs can
+ * show the speed.
+ */
+
+// ClockRules how a self-clocked server ticks
+type ClockRules struct {
+	Period       time.Duration // the tick period at speed level 0
+	Step         time.Duration // how much each speed level takes off of the period
+	Min          time.Duration // the shortest period
+	Max          time.Duration // the longest period, for levels below 0 (0 for no limit)
+	FoodPerLevel int           // how much food speeds the game up a level (0 never)
+}
+
+// DefaultClockRules the clock rules that a server uses if it isn't given any
+func DefaultClockRules() ClockRules {
+	return ClockRules{Period: 200 * time.Millisecond, Step: 10 * time.Millisecond, Min: 50 * time.Millisecond, FoodPerLevel: 5}
+}
+
+// the shortest period that a clock can tick at, whatever the rules
+const minClockPeriod = time.Millisecond
+
+// Interval the tick period for a speed level
+func (cr ClockRules) Interval(level int) time.Duration {
+	p := cr.Period - time.Duration(level)*cr.Step
+	if cr.Min > 0 && p < cr.Min {
+		p = cr.Min
+	}
+	if cr.Max > 0 && p > cr.Max {
+		p = cr.Max
+	}
+	if p < minClockPeriod {
+		p = minClockPeriod
+	}
+	return p
+}
+
+// the clock rules for the server
+func (s *Server) clockRules() ClockRules {
+	if s.ClockRules.Period == 0 {
+		return DefaultClockRules()
+	}
+	return s.ClockRules
+}
+
+// speed the game up a level for every FoodPerLevel food eaten.  A game that is
+// only ticked by Tick commands keeps its speed, unless ClockRules were set.
+func (s *Server) speedUp(rs []game.TickResult) {
+	if !s.clocked && s.ClockRules.Period == 0 {
+		return
+	}
+	cr := s.clockRules()
+	if cr.FoodPerLevel <= 0 {
+		return
+	}
+	for _, r := range rs {
+		if !r.AteFood {
+			continue
+		}
+		s.eaten++
+		if s.eaten%cr.FoodPerLevel == 0 {
+			s.Game.SetSpeed(s.Game.Speed() + 1)
+			if s.Recorder != nil {
+				s.Recorder.Speed(s.Game.Speed())
+			}
+			log.Printf("SPEED: Up to level %d", s.Game.Speed())
+		}
+	}
+}
+
+// Report the clock interval, and keep it for spectator snapshots
+func (s *Server) sendInterval(d time.Duration) {
+	s.interval = d
+	s.emit(Event{Type: EventClock, Interval: d})
+}
+
+// the server clock, which only ticks on C while it is switched on
+type serverClock struct {
+	s      *Server
+	ticker *time.Ticker
+	C      <-chan time.Time // nil while the clock is off, which never ticks
+	period time.Duration
+}
+
+// switch the clock on or off
+func (c *serverClock) switchTo(on bool) {
+	if on == (c.ticker != nil) {
+		return
+	}
+	if on {
+		c.s.clocked = true
+		c.period = c.s.clockRules().Interval(c.s.Game.Speed())
+		c.ticker = time.NewTicker(c.period)
+		c.C = c.ticker.C
+		log.Printf("CLOCK: On, ticking every %s", c.period)
+	} else {
+		c.stop()
+		log.Printf("CLOCK: Off")
+	}
+	c.s.sendInterval(c.period)
+}
+
+// change the period if the game speed has changed
+func (c *serverClock) update() {
+	if c.ticker == nil {
+		return
+	}
+	if p := c.s.clockRules().Interval(c.s.Game.Speed()); p != c.period {
+		c.period = p
+		c.ticker.Reset(p)
+		log.Printf("CLOCK: Now ticking every %s", c.period)
+		c.s.sendInterval(c.period)
+	}
+}
+
+// start the clock period again, so that the next tick is a whole period away
+func (c *serverClock) restart() {
+	if c.ticker != nil {
+		c.ticker.Reset(c.period)
+	}
+}
+
+// stop the clock ticking
+func (c *serverClock) stop() {
+	if c.ticker != nil {
+		c.ticker.Stop()
+	}
+	c.ticker, c.C, c.period = nil, nil, 0
+}
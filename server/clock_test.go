@@ -0,0 +1,106 @@
+package server_test
+
+import (
+	"context"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/server"
+	"testing"
+	"time"
+)
+
+// Test the clock periods for speed levels
+func Test_ClockInterval(t *testing.T) {
+	cr := server.ClockRules{Period: 100 * time.Millisecond, Step: 20 * time.Millisecond, Min: 50 * time.Millisecond, Max: 140 * time.Millisecond}
+
+	for l, p := range map[int]time.Duration{0: 100, 1: 80, 2: 60, 3: 50, 9: 50, -1: 120, -5: 140} {
+		if i := cr.Interval(l); i != p*time.Millisecond {
+			t.Errorf("Clock had the wrong period for level %d: %s", l, i)
+		}
+	}
+	if i := (server.ClockRules{Period: time.Millisecond, Step: time.Millisecond}).Interval(5); i <= 0 {
+		t.Errorf("Clock period without a minimum went to %s", i)
+	}
+}
+
+// wait for a clock event, and check its interval
+func waitInterval(t *testing.T, sub *server.Subscription, want time.Duration) {
+	if e, ok := waitEvent(t, sub, server.EventClock); ok && e.Interval != want {
+		t.Errorf("Received the wrong clock interval: %s != %s", e.Interval, want)
+	}
+}
+
+// Test that a self-clocked server plays the game without any ticks
+func Test_ServerSelfClock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 0, Y: 0})
+	s := server.NewServer(&g)
+	s.SelfClock = true
+	s.ClockRules = server.ClockRules{Period: testTick}
+
+	sub := s.Subscribe(0)
+	go server.NeedFoodHandler(server.NewMakeFood_Slice(nil), s.NeedsFood, ctx)
+	go s.Start(ctx)
+
+	waitInterval(t, sub, testTick)
+	// (5,5) up to the boundary
+	if r, ok := <-s.Finished; !ok || r.Reason != game.HitBoundary || r.Ticks != 5 {
+		t.Errorf("Self-clocked game did not end as expected: %+v", r)
+	}
+}
+
+// Test switching the clock on and off, and speeding it up with food
+func Test_ServerClockSwitch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 100, Y: 100}, game.Point{X: 50, Y: 51})
+	s := server.NewServer(&g)
+	s.ClockRules = server.ClockRules{Period: 20 * time.Millisecond, Step: 5 * time.Millisecond, FoodPerLevel: 1}
+
+	logDeaths(&s, t)
+	sub := s.Subscribe(256)
+	go server.NeedFoodHandler(server.NewMakeFood_Slice([]game.Point{{X: 0, Y: 0}}), s.NeedsFood, ctx)
+	go s.Start(ctx)
+
+	s.SwitchClock(ctx, true)
+	waitInterval(t, sub, 20*time.Millisecond)
+	if e, _ := waitEvent(t, sub, server.EventSpeed); e.Speed != 1 || e.Tick != 1 { // the first tick eats
+		t.Errorf("Eating food did not speed the game up: %s", e)
+	}
+	waitInterval(t, sub, 15*time.Millisecond)
+	s.SwitchClock(ctx, false)
+	waitInterval(t, sub, 0)
+
+	cancel()
+	if r, ok := <-s.Finished; !ok || r.Reason != game.Playing || r.FoodEaten != 1 {
+		t.Errorf("Clocked game did not end as expected: %+v", r)
+	}
+	if g.Speed() != 1 {
+		t.Errorf("Eating food did not speed the game up: %d", g.Speed())
+	}
+}
+
+// Test that eating food doesn't speed up a game that only gets Tick commands
+func Test_ServerTickedSpeed(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 20, Y: 20}, game.Point{X: 10, Y: 11})
+	s := server.NewServer(&g)
+	s.MakeFood = server.NewMakeFood_Slice([]game.Point{{X: 10, Y: 12}, {X: 10, Y: 13}, {X: 10, Y: 14}, {X: 10, Y: 15}, {X: 10, Y: 16}})
+	go s.Start(ctx)
+
+	for i := 0; i < 6; i++ {
+		s.Tick(ctx)
+	}
+	s.View(ctx, func(g *game.Game) {
+		if r, _ := g.Result(0); r.FoodEaten != 6 {
+			t.Errorf("The snake did not eat the food: %+v", r)
+		}
+		if g.Speed() != 0 {
+			t.Errorf("Eating food sped up a ticked game: %d", g.Speed())
+		}
+	})
+}
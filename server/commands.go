@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/james-nesbitt/snake/game"
+)
+
+/**
+ * Commands are how everything outside of the server loop drives a game.  Each
+ * one waits until the server loop takes it, and returns an error instead if:
+ *  1. the server has stopped (ErrGameOver if it stopped because the game is
+ *     over, or ErrStopped otherwise)
+ *  2. the command context is done first (the context error)
+ *
+ * Commands are safe to send from any goroutine, at any time, even after the
+ * server has stopped, as the server never closes its incoming channels.  A
+ * command sent before the server is started waits for it to start.
+ */
+
+var (
+	// ErrStopped the server has stopped, so it can't take commands
+	ErrStopped = errors.New("Could not reach the server, it has stopped")
+	// ErrGameOver the server has stopped because the game is over
+	ErrGameOver = errors.New("Could not reach the server, the game is over")
+	// ErrStarted the server was started more than once
+	ErrStarted = errors.New("Could not start the server, it has already been started")
+)
+
+// Tick the game once
+func (s *Server) Tick(ctx context.Context) error {
+	select {
+	case s.ticks <- struct{}{}:
+		return nil
+	case <-s.done:
+		return s.stoppedErr()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Turn the snake.  A turn that the game rejects is a turn-rejected event, not an
+// error here.
+func (s *Server) Turn(ctx context.Context, dir game.Vector) error {
+	return s.TurnSnake(ctx, 0, dir)
+}
+
+// TurnSnake turn a snake, in a game with more than one
+func (s *Server) TurnSnake(ctx context.Context, id game.SnakeID, dir game.Vector) error {
+	select {
+	case s.turns <- snakeTurn{snake: id, dir: dir}:
+		return nil
+	case <-s.done:
+		return s.stoppedErr()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SwitchClock switch the server clock on (self-clocked) or off (ticked by Tick)
+func (s *Server) SwitchClock(ctx context.Context, on bool) error {
+	select {
+	case s.clocks <- on:
+		return nil
+	case <-s.done:
+		return s.stoppedErr()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pause the game
+func (s *Server) Pause(ctx context.Context) error {
+	return s.sendControl(ctx, Pause)
+}
+
+// Resume a paused game
+func (s *Server) Resume(ctx context.Context) error {
+	return s.sendControl(ctx, Resume)
+}
+
+// Step a paused game on by one tick
+func (s *Server) Step(ctx context.Context) error {
+	return s.sendControl(ctx, Step)
+}
+
+func (s *Server) sendControl(ctx context.Context, c Control) error {
+	select {
+	case s.controls <- c:
+		return nil
+	case <-s.done:
+		return s.stoppedErr()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// View the game from inside of the game loop, where it is safe to read, as it
+// doesn't change while f runs.  f must not change the game, or block, as the game
+// waits for it.  View returns once f has run.
+func (s *Server) View(ctx context.Context, f func(g *game.Game)) error {
+	done := make(chan struct{})
+	view := func(g *game.Game) {
+		defer close(done)
+		f(g)
+	}
+	select {
+	case s.views <- view:
+		<-done
+		return nil
+	case <-s.done:
+		return s.stoppedErr()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ViewFinal view the game like View, but once the server has stopped f is run on
+// the final game (which can't change any more) instead of failing.  It returns
+// true if f saw the final game, and only fails if ctx is done first.
+func (s *Server) ViewFinal(ctx context.Context, f func(g *game.Game)) (bool, error) {
+	if err := s.View(ctx, f); err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		// the server has stopped, so the game can't change
+		<-s.done
+		f(s.Game)
+		return true, nil
+	}
+	return false, nil
+}
+
+// Done a chan which is closed when the server stops
+func (s *Server) Done() <-chan struct{} {
+	return s.done
+}
+
+// the error for a command sent to a stopped server
+func (s *Server) stoppedErr() error {
+	if s.Game.Over() {
+		return ErrGameOver
+	}
+	return ErrStopped
+}
@@ -0,0 +1,102 @@
+package server_test
+
+import (
+	"context"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/server"
+	"testing"
+	"time"
+)
+
+// Test that commands to a game that is over return an error, instead of panicking
+func Test_ServerCommandsGameOver(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 2, Y: 2}, game.Point{X: 0, Y: 0})
+	s := server.NewServer(&g)
+
+	go server.NeedFoodHandler(server.NewMakeFood_Slice(nil), s.NeedsFood, ctx)
+	go s.Start(ctx)
+
+	s.Tick(ctx) // (1,2)
+	s.Tick(ctx) // boundary collision
+	<-s.Done()
+
+	if err := s.Tick(ctx); err != server.ErrGameOver {
+		t.Errorf("Tick after the game was over did not fail: %v", err)
+	}
+	if err := s.Turn(ctx, game.Left); err != server.ErrGameOver {
+		t.Errorf("Turn after the game was over did not fail: %v", err)
+	}
+	if err := s.Pause(ctx); err != server.ErrGameOver {
+		t.Errorf("Pause after the game was over did not fail: %v", err)
+	}
+	if err := s.Start(ctx); err != server.ErrStarted {
+		t.Errorf("Server started a second time: %v", err)
+	}
+}
+
+// Test that commands to a cancelled server return an error
+func Test_ServerCommandsStopped(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 0, Y: 0})
+	s := server.NewServer(&g)
+
+	// a command waits for the server to start, until its own context is done
+	short, shortCancel := context.WithTimeout(ctx, testTick)
+	defer shortCancel()
+	if err := s.Turn(short, game.Left); err != context.DeadlineExceeded {
+		t.Errorf("Turn to a server that wasn't started did not time out: %v", err)
+	}
+
+	sctx, stop := context.WithCancel(ctx)
+	go server.NeedFoodHandler(server.NewMakeFood_Slice(nil), s.NeedsFood, ctx)
+	go s.Start(sctx)
+
+	if err := s.Tick(ctx); err != nil {
+		t.Errorf("Tick to a running server failed: %s", err)
+	}
+	stop()
+	for range s.Finished {
+	}
+
+	if err := s.Tick(ctx); err != server.ErrStopped {
+		t.Errorf("Tick after the server stopped did not fail: %v", err)
+	}
+	if err := s.SwitchClock(ctx, true); err != server.ErrStopped {
+		t.Errorf("Clock switch after the server stopped did not fail: %v", err)
+	}
+}
+
+// Test viewing the game while it runs, and the final game once it has stopped
+func Test_ServerViewFinal(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 2, Y: 2}, game.Point{X: 0, Y: 0})
+	s := server.NewServer(&g)
+	s.RandomFood()
+	go s.Start(ctx)
+
+	var n uint
+	if final, err := s.ViewFinal(ctx, func(g *game.Game) { n = g.Ticks() }); err != nil || final || n != 0 {
+		t.Errorf("Viewing a running game gave %d ticks, final %t: %v", n, final, err)
+	}
+
+	s.Tick(ctx) // (1,2)
+	s.Tick(ctx) // boundary collision
+	<-s.Done()
+	if final, err := s.ViewFinal(ctx, func(g *game.Game) { n = g.Ticks() }); err != nil || !final || n != 2 {
+		t.Errorf("Viewing a finished game gave %d ticks, final %t: %v", n, final, err)
+	}
+
+	done, stop := context.WithCancel(ctx)
+	stop()
+	ns := server.NewServer(&g)
+	if _, err := ns.ViewFinal(done, func(*game.Game) {}); err != context.Canceled {
+		t.Errorf("Viewing with a done context did not fail: %v", err)
+	}
+}
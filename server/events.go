@@ -0,0 +1,251 @@
+package server
+
+import (
+	"fmt"
+	"github.com/james-nesbitt/snake/game"
+	"sync"
+	"time"
+)
+
+/**
+ * Everything that happens in a server game goes out as one ordered stream of
+ * typed events.  Every event has a sequence number (counting from 1, in the
+ * order that the events happened) and the game tick that it happened on.
+ *
+ * A tick goes out as a Ticked event, followed by an event for what each snake
+ * did (Moved, Grew, Ate or Died), any Won event, and then any food placed for
+ * the next tick.
+ *
+ * Any number of subscribers can watch the stream, each with its own buffered
+ * feed, so that a UI, a recorder and a metrics sink can all watch the same game.
+ * The server never waits for a subscriber: if a feed is full then the event is
+ * dropped for that subscriber (and counted), which shows up as a gap in the
+ * sequence numbers.  Feeds are closed when the server stops.
+ */
+
+// EventType what an event is about
+type EventType uint8
+
+const (
+	EventTicked       EventType = iota // the game ticked
+	EventMoved                         // a snake moved, to Point
+	EventGrew                          // a snake moved and grew, to Point
+	EventAte                           // a snake ate Food
+	EventTurned                        // a snake turned, to Dir
+	EventTurnRejected                  // a turn to Dir was rejected, for Err
+	EventFoodPlaced                    // Food was placed on the board
+	EventFoodRejected                  // new Food was rejected, for Err
+	EventDied                          // a snake died at Point, for Reason
+	EventWon                           // a snake won by filling the board
+	EventPaused                        // the game was paused
+	EventResumed                       // the game was resumed
+	EventSpeed                         // the game speed changed, to Speed
+	EventClock                         // the server clock period changed, to Interval (0 when it was switched off)
+)
+
+// the event types, in order
+var eventTypes = []EventType{EventTicked, EventMoved, EventGrew, EventAte, EventTurned, EventTurnRejected, EventFoodPlaced, EventFoodRejected, EventDied, EventWon, EventPaused, EventResumed, EventSpeed, EventClock}
+
+// Convert to a printable string
+func (et EventType) String() string {
+	switch et {
+	case EventTicked:
+		return "ticked"
+	case EventMoved:
+		return "moved"
+	case EventGrew:
+		return "grew"
+	case EventAte:
+		return "ate"
+	case EventTurned:
+		return "turned"
+	case EventTurnRejected:
+		return "turn-rejected"
+	case EventFoodPlaced:
+		return "food-placed"
+	case EventFoodRejected:
+		return "food-rejected"
+	case EventDied:
+		return "died"
+	case EventWon:
+		return "won"
+	case EventPaused:
+		return "paused"
+	case EventResumed:
+		return "resumed"
+	case EventSpeed:
+		return "speed"
+	case EventClock:
+		return "clock"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText the event type as its name
+func (et EventType) MarshalText() ([]byte, error) {
+	if et.String() == "unknown" {
+		return nil, fmt.Errorf("Unknown event type %d", et)
+	}
+	return []byte(et.String()), nil
+}
+
+// UnmarshalText an event type from its name
+func (et *EventType) UnmarshalText(b []byte) error {
+	for _, t := range eventTypes {
+		if t.String() == string(b) {
+			*et = t
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown event type %q", b)
+}
+
+// Event something that happened in a server game.  Only the fields that the
+// event type uses are set.
+type Event struct {
+	Seq      uint64         `json:"seq"`
+	Tick     uint           `json:"tick"` // the game tick that the event happened on
+	Type     EventType      `json:"type"`
+	Snake    game.SnakeID   `json:"snake"`
+	Point    game.Point     `json:"point"`              // the snake head, for snake events
+	Dir      game.Vector    `json:"dir"`                // the direction, for turns
+	Food     game.Food      `json:"food"`               // the food, for food events
+	Points   int            `json:"points,omitempty"`   // the points scored, for moves
+	Reason   game.EndReason `json:"reason,omitempty"`   // how the snake's game ended, for deaths and wins
+	Speed    int            `json:"speed,omitempty"`    // the game speed level, for speed changes
+	Interval time.Duration  `json:"interval,omitempty"` // the clock period, for clock changes
+	Err      error          `json:"-"`                  // what went wrong, for deaths and rejections
+}
+
+// Convert to a printable string
+func (e Event) String() string {
+	s := fmt.Sprintf("#%d [tick %d] %s", e.Seq, e.Tick, e.Type)
+	switch e.Type {
+	case EventMoved, EventGrew, EventDied, EventWon:
+		s += fmt.Sprintf(" [Snake %d: %s]", e.Snake, e.Point)
+	case EventAte, EventFoodPlaced, EventFoodRejected:
+		s += fmt.Sprintf(" [%s]", e.Food)
+	case EventTurned, EventTurnRejected:
+		s += fmt.Sprintf(" [Snake %d: %s]", e.Snake, e.Dir)
+	case EventSpeed:
+		s += fmt.Sprintf(" [%d]", e.Speed)
+	case EventClock:
+		s += fmt.Sprintf(" [%s]", e.Interval)
+	}
+	if e.Err != nil {
+		s += fmt.Sprintf(" [%s]", e.Err)
+	}
+	return s
+}
+
+// DefaultEventBuffer how many events a subscription buffers, if it isn't told
+const DefaultEventBuffer = 64
+
+// Subscription a feed of server events, which is closed when the server stops
+// or the subscription is cancelled
+type Subscription struct {
+	C <-chan Event
+
+	c       chan Event
+	bus     *eventBus
+	dropped uint64 // guarded by the bus
+	delayed bool   // a delayed spectator, which is sent held back events
+}
+
+// Dropped how many events were dropped because the feed was full
+func (sub *Subscription) Dropped() uint64 {
+	sub.bus.mu.Lock()
+	defer sub.bus.mu.Unlock()
+	return sub.dropped
+}
+
+// Unsubscribe stop the feed, and close it
+func (sub *Subscription) Unsubscribe() {
+	sub.bus.mu.Lock()
+	defer sub.bus.mu.Unlock()
+	for i, o := range sub.bus.subs {
+		if o == sub {
+			sub.bus.subs = append(sub.bus.subs[:i], sub.bus.subs[i+1:]...)
+			close(sub.c)
+			return
+		}
+	}
+}
+
+// the subscriptions to a server's events
+type eventBus struct {
+	mu     sync.Mutex
+	seq    uint64
+	subs   []*Subscription
+	closed bool
+	frames []spectatorFrame // held back for delayed spectators (see spectate.go)
+}
+
+// Subscribe to the server events, with a feed that buffers up to buffer events
+// (DefaultEventBuffer if it is less than 1).  Subscribing to a stopped server
+// gives a closed feed.  A subscription is always live, even with a
+// SpectatorDelay, so it is only for the players (and trusted consumers, like a
+// recorder): watchers should Spectate instead.
+func (s *Server) Subscribe(buffer int) *Subscription {
+	if buffer < 1 {
+		buffer = DefaultEventBuffer
+	}
+	c := make(chan Event, buffer)
+	sub := &Subscription{C: c, c: c, bus: s.events}
+
+	s.events.mu.Lock()
+	defer s.events.mu.Unlock()
+	if s.events.closed {
+		close(c)
+	} else {
+		s.events.subs = append(s.events.subs, sub)
+	}
+	return sub
+}
+
+// Send an event to every subscriber, stamping it with its sequence number and
+// the game tick.  It never blocks the game loop.
+func (s *Server) emit(e Event) {
+	e.Tick = s.Game.Ticks()
+
+	s.events.mu.Lock()
+	defer s.events.mu.Unlock()
+	if s.events.closed {
+		return
+	}
+	s.events.seq++
+	e.Seq = s.events.seq
+	if n := len(s.events.frames); n > 0 {
+		s.events.frames[n-1].events = append(s.events.frames[n-1].events, e)
+	}
+	for _, sub := range s.events.subs {
+		if sub.delayed {
+			continue
+		}
+		select {
+		case sub.c <- e:
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+// close every subscription, once the server has stopped.  Delayed spectators
+// get everything that was held back first.
+func (b *eventBus) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	for _, f := range b.frames {
+		b.release(f.events)
+	}
+	b.frames = nil
+	b.closed = true
+	for _, sub := range b.subs {
+		close(sub.c)
+	}
+	b.subs = nil
+}
@@ -0,0 +1,237 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/james-nesbitt/snake/game"
+	"log"
+	"time"
+)
+
+/**
+ * The server asks for food on the NeedsFood chan, but never waits on it for
+ * longer than the FoodTimeout.  A food request goes like this:
+ *  1. the request is sent, and its food is collected until the food chan is
+ *     closed, all within the FoodTimeout
+ *  2. each item of food is checked: food off of the grid, on an obstacle, on
+ *     other food, or on a snake is rejected (a food-rejected event)
+ *  3. if any food was rejected, the shortfall is asked for again, up to
+ *     FoodRetries times
+ *  4. if the request timed out (nobody listened, or the maker was too slow), or
+ *     food was still being rejected after the retries, the shortfall is made by
+ *     the FallbackFood maker instead
+ *
+ * A maker that closes the food chan without sending any food (because it can't
+ * make any) isn't a failure, and the game carries on without the food.
+ *
+ * The request food chan is buffered for the whole Count, so a maker that answers
+ * after the timeout never blocks, and its food is just dropped.  Once the server
+ * stops waiting it closes the request Done chan, so that the maker can give up on
+ * the request instead of making food for nobody.
+ *
+ * A server with a MakeFood makes its own food on the server loop instead, where
+ * the maker can safely read the game, and never asks on the NeedsFood chan.  Food
+ * from the MakeFood that is rejected is made by the FallbackFood maker.
+ *
+ * The server never asks for more food than there are free points on the board.
+ */
+
+const (
+	// DefaultFoodTimeout how long the server waits for food, if it isn't told
+	DefaultFoodTimeout = 250 * time.Millisecond
+	// DefaultFoodRetries how many times the server asks again for rejected food,
+	// if it isn't told
+	DefaultFoodRetries = 2
+)
+
+// how long to wait for food, where 0 means forever
+func (s *Server) foodTimeout() time.Duration {
+	switch {
+	case s.FoodTimeout == 0:
+		return DefaultFoodTimeout
+	case s.FoodTimeout < 0:
+		return 0
+	default:
+		return s.FoodTimeout
+	}
+}
+
+// how many times to ask again for rejected food
+func (s *Server) foodRetries() int {
+	switch {
+	case s.FoodRetries == 0:
+		return DefaultFoodRetries
+	case s.FoodRetries < 0:
+		return 0
+	default:
+		return s.FoodRetries
+	}
+}
+
+// the food maker to fall back on
+func (s *Server) fallbackFood() MakeFood {
+	if s.FallbackFood == nil {
+		s.FallbackFood = NewMakeFood_Random(s.Game)
+	}
+	return s.FallbackFood
+}
+
+// the kind and value of the food that is needed
+func (s *Server) chooseFood() (game.FoodKind, int) {
+	if s.ChooseFood == nil {
+		return game.NormalFood, 0
+	}
+	return s.ChooseFood(s.Game)
+}
+
+// Get n new food items onto the board, returning false if the context was done
+// while asking for them
+func (s *Server) needFood(ctx context.Context, n int) bool {
+	if free := s.Game.FreeCount(); n > free {
+		n = free
+	}
+
+	if s.MakeFood != nil {
+		made, rejected := s.makeFood(s.MakeFood, n)
+		if rejected == 0 {
+			return true
+		}
+		n -= made
+	} else {
+		var ok bool
+		if n, ok = s.askFood(ctx, n); !ok {
+			return false
+		}
+	}
+
+	if n > 0 {
+		s.makeFood(s.fallbackFood(), n)
+	}
+	return true
+}
+
+// Ask for n food on the NeedsFood chan, retrying rejected food, and return how
+// much of it the fallback maker should make.  ok is false if the context was done.
+func (s *Server) askFood(ctx context.Context, n int) (short int, ok bool) {
+	for try := 0; n > 0; try++ {
+		log.Printf("FOOD: Asking for %d new food", n)
+		made, rejected, answered, ok := s.requestFood(ctx, n)
+		if !ok {
+			return n, false
+		}
+		n -= made
+
+		if !answered {
+			log.Printf("FOOD: No answer in time, falling back")
+			return n, true
+		}
+		if rejected == 0 {
+			if made == 0 {
+				log.Printf("FOOD: No food could be created")
+			}
+			return 0, true
+		}
+		if try >= s.foodRetries() {
+			log.Printf("FOOD: Food was still rejected after %d retries, falling back", try)
+			return n, true
+		}
+	}
+	return 0, true
+}
+
+// Make n food with a maker, on the server loop, and place it, returning how much
+// was placed and rejected
+func (s *Server) makeFood(mf MakeFood, n int) (made, rejected int) {
+	ps, err := nextFoods(mf, n)
+	if err != nil {
+		log.Printf("FOOD: Could not make food: %s", err)
+	}
+	k, v := s.chooseFood()
+	for _, p := range ps {
+		if err := s.placeFood(game.Food{Point: p, Kind: k, Value: v}); err != nil {
+			rejected++
+			continue
+		}
+		made++
+	}
+	return made, rejected
+}
+
+// Send a request for n food, and place the food that comes back, returning how
+// much was placed and rejected, and whether the request was answered in time.  ok
+// is false if the context was done.
+func (s *Server) requestFood(ctx context.Context, n int) (made, rejected int, answered, ok bool) {
+	var timeout <-chan time.Time
+	if d := s.foodTimeout(); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	k, v := s.chooseFood()
+	req := NewFoodRequest(k, n) // New food request, to receive new food on
+	req.Value = v
+	defer req.cancel() // whatever happens, the server is done with the request
+	select {
+	case s.needsFood <- req: // send out a signal that we need new food
+	case <-timeout:
+		return 0, 0, false, true
+	case <-ctx.Done():
+		log.Printf("FOOD: Stopped while asking for food")
+		return 0, 0, false, false
+	}
+
+	for { // receive new food until the maker closes the chan
+		select {
+		case food, open := <-req.Food:
+			if !open {
+				return made, rejected, true, true
+			}
+			if made >= n {
+				log.Printf("FOOD: Dropped extra %s", food)
+				continue
+			}
+			if err := s.placeFood(food); err != nil {
+				rejected++
+				continue
+			}
+			made++
+		case <-timeout:
+			return made, rejected, false, true
+		case <-ctx.Done():
+			log.Printf("FOOD: Stopped while waiting for food")
+			return made, rejected, false, false
+		}
+	}
+}
+
+// Check and place new food.  Unlike the game, the server doesn't let food be
+// placed on a snake.
+func (s *Server) placeFood(food game.Food) error {
+	err := s.checkFood(food)
+	if err == nil {
+		err = s.Game.AddFood(food)
+	}
+	if err != nil {
+		log.Printf("FOOD: Rejected new food: %s", err)
+		s.emit(Event{Type: EventFoodRejected, Food: food, Err: err})
+		return err
+	}
+
+	if s.Recorder != nil {
+		s.Recorder.Food(food)
+	}
+	log.Printf("FOOD: New %s", food)
+	s.emit(Event{Type: EventFoodPlaced, Food: food})
+	return nil
+}
+
+// check that food isn't on a snake, leaving everything else to the game
+func (s *Server) checkFood(food game.Food) error {
+	for _, id := range s.Game.Living() {
+		if sn, _ := s.Game.Snake(id); sn.Contains(food.Point) {
+			return fmt.Errorf("Could not place %s, it is on snake %d", food, id)
+		}
+	}
+	return nil
+}
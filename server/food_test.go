@@ -0,0 +1,192 @@
+package server_test
+
+import (
+	"context"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/server"
+	"testing"
+	"time"
+)
+
+// start a server for a game where the snake eats the food on the first tick,
+// and return the food events from the first two ticks
+func foodEvents(t *testing.T, s *server.Server) []server.Event {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	sub := s.Subscribe(256)
+	go s.Start(ctx)
+
+	for i := 0; i < 2; i++ {
+		if err := s.Tick(ctx); err != nil {
+			t.Fatalf("Tick failed while waiting for food: %s", err)
+		}
+	}
+	cancel()
+	for range s.Finished {
+	}
+
+	es := []server.Event{}
+	for e := range sub.C {
+		if e.Type == server.EventFoodPlaced || e.Type == server.EventFoodRejected {
+			es = append(es, e)
+		}
+	}
+	return es
+}
+
+// a game with the snake at (5,5) facing down, and food at (5,6)
+func foodServer() (*game.Game, server.Server) {
+	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 5, Y: 6})
+	return &g, server.NewServer(&g)
+}
+
+// check the food events, by type and food point
+func checkFoodEvents(t *testing.T, es []server.Event, want ...server.Event) {
+	if len(es) != len(want) {
+		t.Fatalf("Received the wrong food events: %v", es)
+	}
+	for i, w := range want {
+		if es[i].Type != w.Type || es[i].Food.Point != w.Food.Point {
+			t.Errorf("Received the wrong food event: %s != %s", es[i], w)
+		}
+	}
+}
+
+// Test that the fallback food is used when nobody answers a food request
+func Test_ServerFoodTimeout(t *testing.T) {
+	_, s := foodServer()
+	s.FoodTimeout = testTick
+	s.FallbackFood = server.NewMakeFood_Slice([]game.Point{{X: 8, Y: 8}})
+
+	checkFoodEvents(t, foodEvents(t, &s), server.Event{Type: server.EventFoodPlaced, Food: game.NewFood(game.Point{X: 8, Y: 8})})
+}
+
+// Test that food from a slow food maker is dropped
+func Test_ServerFoodSlow(t *testing.T) {
+	g, s := foodServer()
+	s.FoodTimeout = testTick
+	s.FallbackFood = server.NewMakeFood_Slice([]game.Point{{X: 8, Y: 8}})
+
+	go func() {
+		for req := range s.NeedsFood {
+			time.Sleep(10 * testTick)
+			req.Food <- game.NewFood(game.Point{X: 1, Y: 1}) // never blocks
+			close(req.Food)
+		}
+	}()
+
+	checkFoodEvents(t, foodEvents(t, &s), server.Event{Type: server.EventFoodPlaced, Food: game.NewFood(game.Point{X: 8, Y: 8})})
+	if fs := g.Foods(); len(fs) != 1 || fs[0].Point != (game.Point{X: 8, Y: 8}) {
+		t.Errorf("Game has the wrong food: %v", fs)
+	}
+}
+
+// Test that bad food is rejected, and asked for again
+func Test_ServerFoodRetry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, s := foodServer()
+	// off of the grid, on the snake, and then fine
+	go server.NeedFoodHandler(server.NewMakeFood_Slice([]game.Point{{X: 20, Y: 20}, {X: 5, Y: 5}, {X: 9, Y: 9}}), s.NeedsFood, ctx)
+
+	checkFoodEvents(t, foodEvents(t, &s),
+		server.Event{Type: server.EventFoodRejected, Food: game.NewFood(game.Point{X: 20, Y: 20})},
+		server.Event{Type: server.EventFoodRejected, Food: game.NewFood(game.Point{X: 5, Y: 5})},
+		server.Event{Type: server.EventFoodPlaced, Food: game.NewFood(game.Point{X: 9, Y: 9})},
+	)
+}
+
+// Test that the fallback food is used when food is still rejected after retrying
+func Test_ServerFoodRetriesFallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, s := foodServer()
+	s.FoodRetries = -1
+	s.FallbackFood = server.NewMakeFood_Slice([]game.Point{{X: 7, Y: 7}})
+	go server.NeedFoodHandler(server.NewMakeFood_Slice([]game.Point{{X: 5, Y: 6}}), s.NeedsFood, ctx)
+
+	checkFoodEvents(t, foodEvents(t, &s),
+		server.Event{Type: server.EventFoodRejected, Food: game.NewFood(game.Point{X: 5, Y: 6})},
+		server.Event{Type: server.EventFoodPlaced, Food: game.NewFood(game.Point{X: 7, Y: 7})},
+	)
+}
+
+// Test that a server MakeFood makes all of the food on the server loop, with no
+// food on the same point
+func Test_ServerMakeFood(t *testing.T) {
+	g, s := foodServer()
+	s.FoodCount = 5
+	s.RandomFood()
+
+	// the snake can eat some of the food on the second tick, so only count the first
+	n := 0
+	for _, e := range foodEvents(t, &s) {
+		if e.Tick == 1 {
+			n++
+		}
+	}
+	if n != 5 {
+		t.Errorf("Received %d food events on the first tick, when 5 were needed", n)
+	}
+	seen := map[game.Point]bool{}
+	for _, f := range g.Foods() {
+		if seen[f.Point] {
+			t.Errorf("Food was made twice at %s", f.Point)
+		}
+		seen[f.Point] = true
+	}
+	if len(seen) != 5 {
+		t.Errorf("Game has the wrong food: %v", g.Foods())
+	}
+}
+
+// Test that the server asks for, and places, the kind of food that ChooseFood
+// picks, from a NeedFoodHandler and from a MakeFood
+func Test_ServerChooseFood(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, local := range []bool{false, true} {
+		_, s := foodServer()
+		s.ChooseFood = func(g *game.Game) (game.FoodKind, int) { return game.GrowFood, 3 }
+		mf := server.NewMakeFood_Slice([]game.Point{{X: 5, Y: 7}})
+		if local {
+			s.MakeFood = mf
+		} else {
+			go server.NeedFoodHandler(mf, s.NeedsFood, ctx)
+		}
+
+		es := foodEvents(t, &s)
+		if len(es) != 1 || es[0].Type != server.EventFoodPlaced || es[0].Food.Kind != game.GrowFood || es[0].Food.Value != 3 {
+			t.Errorf("Received the wrong food events: %v", es)
+		}
+	}
+}
+
+// Test that a food request is cancelled once the server stops waiting for it
+func Test_ServerFoodCancel(t *testing.T) {
+	_, s := foodServer()
+	s.FoodTimeout = testTick
+	s.FallbackFood = server.NewMakeFood_Slice([]game.Point{{X: 8, Y: 8}})
+
+	cancelled := make(chan bool, 1)
+	go func() {
+		for req := range s.NeedsFood {
+			select {
+			case <-req.Done:
+				cancelled <- req.Cancelled()
+			case <-time.After(100 * testTick):
+				cancelled <- false
+			}
+			close(req.Food)
+		}
+	}()
+
+	checkFoodEvents(t, foodEvents(t, &s), server.Event{Type: server.EventFoodPlaced, Food: game.NewFood(game.Point{X: 8, Y: 8})})
+	if !<-cancelled {
+		t.Errorf("Food request was not cancelled after the timeout")
+	}
+}
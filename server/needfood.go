@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"github.com/james-nesbitt/snake/game"
 	"log"
 	"math/rand"
@@ -16,14 +17,92 @@ import (
  *
  * Options are:
  *  1. random new food position, but make sure to not put food on top of the snake
+ *     or on an obstacle
  *  2. pull next food positions from an array for deterministic testing
  *  3. put new food in a relational position from the previous food position for
  *     relational testing.
+ *
+ * The game aware makers never put food on an obstacle.  The slice maker has no
+ * game, and so returns exactly the points that it was given.
+ *
+ * The makers only pick points.  The NeedsFood request says what kind of food is
+ * wanted, and how many, and the handler makes that food at the points.  All of the
+ * points for a request are picked at once, before any food is sent, and are all
+ * different.
+ *
+ * The NeedFoodHandler runs beside the server, so it must only be given a maker
+ * that doesn't read the game (like the slice maker): the server loop changes the
+ * game while the handler runs.  A game aware maker goes in the Server MakeFood
+ * instead, where it runs on the server loop.
  */
 
-// Something that can MakeFood points
+// FoodRequest a request for new food, sent out on the NeedsFood chan
+type FoodRequest struct {
+	Kind  game.FoodKind  // the kind of food wanted
+	Value int            // the value for the food, for kinds that need one
+	Count int            // how many items of food are wanted
+	Food  chan game.Food // the chan to send the food on, closed by the food maker when it is done
+
+	// Done is closed once the server has stopped waiting for the food (it timed
+	// out, or stopped), so that the food maker can give up on the request
+	Done   <-chan struct{}
+	cancel func()
+}
+
+// NewFoodRequest a request for some food of a kind.  The food chan is buffered
+// for the whole count, so that sending the food never blocks.
+func NewFoodRequest(k game.FoodKind, n int) FoodRequest {
+	if n < 0 {
+		n = 0
+	}
+	done := make(chan struct{})
+	return FoodRequest{Kind: k, Count: n, Food: make(chan game.Food, n), Done: done, cancel: func() { close(done) }}
+}
+
+// Cancelled has the server stopped waiting for the food
+func (req FoodRequest) Cancelled() bool {
+	select {
+	case <-req.Done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Something that can MakeFood points, or return an error if it can't, such as
+// game.ErrBoardFull
 type MakeFood interface {
-	NextFood() game.Point
+	NextFood() (game.Point, error)
+}
+
+// Something that can make many different food points at once, like the random
+// maker.  Other makers are asked for one point at a time.
+type MakeFoods interface {
+	NextFoods(n int) ([]game.Point, error)
+}
+
+// nextFoods n different points from a maker, or fewer if it can't make them all,
+// with the error that stopped it
+func nextFoods(mf MakeFood, n int) ([]game.Point, error) {
+	if mfs, ok := mf.(MakeFoods); ok {
+		return mfs.NextFoods(n)
+	}
+
+	ps := []game.Point{}
+	picked := map[game.Point]bool{}
+	// a maker that keeps repeating itself gets a few more tries, and then stops
+	for tries := 0; len(ps) < n && tries < 2*n; tries++ {
+		p, err := mf.NextFood()
+		if err != nil {
+			return ps, err
+		}
+		if picked[p] {
+			continue
+		}
+		picked[p] = true
+		ps = append(ps, p)
+	}
+	return ps, nil
 }
 
 /**
@@ -31,21 +110,44 @@ type MakeFood interface {
  * @USAGE use this as a subroutine for responding to a NeedsFood chan
  *
  * @param MakeFood mf : a food maker which will make food whenever it is needed
- * @param chan chan game.Point nf : the channel which indicates that food is needed
- *    and provides a chan for returning the new food point
+ * @param <-chan FoodRequest nf : the channel which indicates that food is needed,
+ *    with a request that says what food and provides a chan for returning it.  The
+ *    food chan is closed once the food is sent, or as soon as no more food can be
+ *    made, so fewer items than were asked for may be sent.  A request that the
+ *    server has given up on (its Done chan is closed) gets no food.
  * @param context.Context ctx : a kill context provider
  */
-func NeedFoodHandler(mf MakeFood, nf chan chan game.Point, ctx context.Context) {
+func NeedFoodHandler(mf MakeFood, nf <-chan FoodRequest, ctx context.Context) {
 	log.Printf("Starting to listen for NeedFood events")
 	for {
 		select {
-		case fc := <-nf:
-			log.Printf("Received request for new Food location")
-			fc <- mf.NextFood()
-			log.Printf("Sent new food location")
+		case req, ok := <-nf:
+			if !ok {
+				// the server closes the chan when it stops
+				return
+			}
+			log.Printf("Received request for %d new %s Food", req.Count, req.Kind)
+			if req.Cancelled() {
+				log.Printf("Dropped a food request that the server gave up on")
+				close(req.Food)
+				continue
+			}
+			ps, err := nextFoods(mf, req.Count)
+			if err != nil {
+				log.Printf("Could not make food: %s", err)
+			}
+			if req.Cancelled() {
+				// don't send stale food, that the server won't check
+				ps = nil
+			}
+			for _, p := range ps {
+				req.Food <- game.Food{Point: p, Kind: req.Kind, Value: req.Value}
+				log.Printf("Sent new food location")
+			}
 
-			// @NOTE originally we closed the channel, but most implementations will
-			//       reuse that passed food chan, and we should let them close it.
+			// @NOTE originally the server closed the channel, but only the maker knows
+			//       when it has sent all of the food that it can.
+			close(req.Food)
 		case <-ctx.Done():
 			return
 		}
@@ -56,38 +158,107 @@ func NewMakeFood_Move(g *game.Game, m game.Vector) MakeFood {
 	return &MakeFood_Move{g: g, m: m}
 }
 
-// Move the food in a Vector every time
+// Move the food in a Vector every time, moving again to step over obstacles
 type MakeFood_Move struct {
 	g *game.Game
 	m game.Vector
 }
 
-func (mf *MakeFood_Move) NextFood() game.Point {
+func (mf *MakeFood_Move) NextFood() (game.Point, error) {
 	hp, _ := mf.g.Food()
 	np := hp.Move(mf.m)
+
+	// a wall can't be longer than the grid is big
+	sz := mf.g.Size()
+	for i := 0; mf.g.Blocked(np) && i < (sz.X+1)*(sz.Y+1); i++ {
+		np = np.Move(mf.m)
+	}
 	log.Printf("Moved food from %s to %s", hp, np)
-	return np
+	return np, nil
 }
 
+// NewMakeFood_Random random food, using the game random numbers, so that the
+// food is reproducible from the game seed (and survives game snapshots)
 func NewMakeFood_Random(g *game.Game) MakeFood {
-	return &MakeFood_Random{g: g}
+	return &MakeFood_Random{g: g, r: g.Random()}
 }
 
-// Return the next point from a source slice
+// NewMakeFood_RandomSeed random food, using its own seeded random numbers
+func NewMakeFood_RandomSeed(g *game.Game, seed int64) MakeFood {
+	return &MakeFood_Random{g: g, r: game.NewRandom(seed)}
+}
+
+// NewMakeFood_RandomSource random food, using a random source
+func NewMakeFood_RandomSource(g *game.Game, src rand.Source) MakeFood {
+	return &MakeFood_Random{g: g, r: rand.New(src)}
+}
+
+// Something that can produce random ints, like a game.Random or a rand.Rand
+type randomIntn interface {
+	Intn(n int) int
+}
+
+// Return a random free point
 type MakeFood_Random struct {
 	g *game.Game
+	r randomIntn
+}
+
+// how many times to guess at a free point before listing them all
+const randomFoodGuesses = 16
+
+// NextFood a point picked uniformly from the free points in the grid
+func (mf *MakeFood_Random) NextFood() (game.Point, error) {
+	ps, err := mf.NextFoods(1)
+	if err != nil {
+		return game.Point{}, err
+	}
+	return ps[0], nil
 }
 
-func (mf *MakeFood_Random) NextFood() game.Point {
-	var f game.Point // Use this to hold food points while we try to find one
+// NextFoods n different points picked uniformly from the free points in the grid,
+// or as many as there are.
+//
+// On an emptyish board a few random guesses will find free points quickly, and
+// only when they all miss do we list the free points and pick from them.  Both
+// ways pick uniformly, so together they do too, in bounded time.
+func (mf *MakeFood_Random) NextFoods(n int) ([]game.Point, error) {
+	if mf.g.Full() {
+		return nil, game.ErrBoardFull
+	}
+
+	ps := []game.Point{}
+	picked := map[game.Point]bool{}
 	sz := mf.g.Size()
-	for {
-		f := game.Point{X: rand.Intn(sz.X), Y: rand.Intn(sz.Y)}
-		if !mf.g.Head().FindPoint(f) {
-			break
+	for misses := 0; len(ps) < n && misses < randomFoodGuesses; {
+		// The grid includes its X and Y edges
+		f := game.Point{X: mf.r.Intn(sz.X + 1), Y: mf.r.Intn(sz.Y + 1)}
+		if picked[f] || !mf.g.Free(f) {
+			misses++
+			continue
 		}
+		picked[f] = true
+		ps = append(ps, f)
 	}
-	return f
+
+	if len(ps) < n {
+		fs := []game.Point{}
+		for _, f := range mf.g.FreeCells() {
+			if !picked[f] {
+				fs = append(fs, f)
+			}
+		}
+		for len(ps) < n && len(fs) > 0 {
+			i := mf.r.Intn(len(fs))
+			ps = append(ps, fs[i])
+			fs[i] = fs[len(fs)-1]
+			fs = fs[:len(fs)-1]
+		}
+	}
+	if len(ps) == 0 {
+		return nil, game.ErrBoardFull
+	}
+	return ps, nil
 }
 
 func NewMakeFood_Slice(ps []game.Point) MakeFood {
@@ -99,8 +270,11 @@ type MakeFood_Slice struct {
 	Points []game.Point
 }
 
-func (mf *MakeFood_Slice) NextFood() game.Point {
+func (mf *MakeFood_Slice) NextFood() (game.Point, error) {
 	var f game.Point
+	if len(mf.Points) == 0 {
+		return f, errors.New("No food points left in the slice")
+	}
 	f, mf.Points = mf.Points[0], mf.Points[1:]
-	return f
+	return f, nil
 }
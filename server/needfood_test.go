@@ -4,14 +4,14 @@ import (
 	"context"
 	"github.com/james-nesbitt/snake/game"
 	"github.com/james-nesbitt/snake/server"
+	"math/rand"
 	"testing"
 	"time"
 )
 
 // Test the NeedsFood handler
 func Test_NeedsFoodBase(t *testing.T) {
-	nfc := make(chan chan game.Point)
-	nf := make(chan game.Point)
+	nfc := make(chan server.FoodRequest)
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	ef := game.Point{X: 3, Y: 4}
@@ -19,17 +19,70 @@ func Test_NeedsFoodBase(t *testing.T) {
 
 	go server.NeedFoodHandler(mf, nfc, ctx)
 
-	nfc <- nf
-	gf := <-nf
+	gf := askFood(nfc)
 	if !gf.Equals(ef) {
 		t.Errorf("Needs Food handling function did not return expected food")
 	}
 }
 
+// Test that the NeedsFood handler makes the kind and count of food asked for, and
+// closes the food chan when it runs out
+func Test_NeedsFoodRequest(t *testing.T) {
+	nfc := make(chan server.FoodRequest)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	fs := []game.Point{{X: 1, Y: 2}, {X: 3, Y: 4}, {X: 5, Y: 6}}
+
+	go server.NeedFoodHandler(server.NewMakeFood_Slice(fs), nfc, ctx)
+
+	req := server.NewFoodRequest(game.GrowFood, 2)
+	req.Value = 3
+	nfc <- req
+	n := 0
+	for f := range req.Food {
+		if !f.Point.Equals(fs[n]) || f.Kind != game.GrowFood || f.Value != 3 {
+			t.Errorf("Food handler made the wrong food: %s", f)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Errorf("Food handler made %d food when 2 were asked for", n)
+	}
+
+	// only one point is left, so asking for more gets what there is
+	req = server.NewFoodRequest(game.NormalFood, 5)
+	nfc <- req
+	n = 0
+	for range req.Food {
+		n++
+	}
+	if n != 1 {
+		t.Errorf("Food handler made %d food when only 1 could be made", n)
+	}
+}
+
+// Test that the NeedsFood handler never sends the same point twice for a request
+func Test_NeedsFoodDistinct(t *testing.T) {
+	nfc := make(chan server.FoodRequest)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go server.NeedFoodHandler(&NeedsFood_Mock{Food: game.Point{X: 3, Y: 4}}, nfc, ctx)
+
+	req := server.NewFoodRequest(game.NormalFood, 3)
+	nfc <- req
+	n := 0
+	for range req.Food {
+		n++
+	}
+	if n != 1 {
+		t.Errorf("Food handler made %d food on the one point that the maker gives", n)
+	}
+}
+
 // Test the moving NeedsFood handler
 func Test_NeedsFoodMove(t *testing.T) {
-	nfc := make(chan chan game.Point)
-	nf := make(chan game.Point)
+	nfc := make(chan server.FoodRequest)
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	mv := game.Vector{X: 1, Y: 2}
@@ -49,8 +102,7 @@ func Test_NeedsFoodMove(t *testing.T) {
 	}
 
 	for i := 0; i < 5; i++ {
-		nfc <- nf
-		gf := <-nf
+		gf := askFood(nfc)
 
 		g.SetFood(gf)
 		t.Logf("Food moved to %s", gf)
@@ -63,10 +115,29 @@ func Test_NeedsFoodMove(t *testing.T) {
 	}
 }
 
+// Test that the moving NeedsFood handler steps over obstacles
+func Test_NeedsFoodMoveObstacle(t *testing.T) {
+	nfc := make(chan server.FoodRequest)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	g, err := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 3, Y: 2})
+	if err != nil {
+		t.Errorf("Game construction error: %s", err)
+	}
+	if err := g.SetObstacles(game.NewObstacles(game.Point{X: 4, Y: 2}, game.Point{X: 5, Y: 2})); err != nil {
+		t.Errorf("Game obstacle error: %s", err)
+	}
+
+	go server.NeedFoodHandler(server.NewMakeFood_Move(&g, game.Vector{X: 1}), nfc, ctx)
+
+	if gf := askFood(nfc); !gf.Equals(game.Point{X: 6, Y: 2}) {
+		t.Errorf("Move Food handler did not step over the obstacles: %s", gf)
+	}
+}
+
 // Test slice based NeedsFood handler
 func Test_NeedsFoodSlice(t *testing.T) {
-	nfc := make(chan chan game.Point)
-	nf := make(chan game.Point)
+	nfc := make(chan server.FoodRequest)
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	f := game.Point{X: 3, Y: 2}
@@ -93,8 +164,7 @@ func Test_NeedsFoodSlice(t *testing.T) {
 	}
 
 	for _, ef := range fs {
-		nfc <- nf
-		gf := <-nf
+		gf := askFood(nfc)
 
 		g.SetFood(gf)
 		t.Logf("Food moved to %s", gf)
@@ -106,11 +176,108 @@ func Test_NeedsFoodSlice(t *testing.T) {
 	}
 }
 
+// Test that random food only lands on free points, and can land on all of them
+func Test_NeedsFoodRandom(t *testing.T) {
+	g, err := game.AutoGame(game.Vector{X: 3, Y: 3}, game.Point{X: 0, Y: 0}) // snake at (1,1)
+	if err != nil {
+		t.Errorf("Game construction error: %s", err)
+	}
+	if err := g.SetObstacles(game.NewObstacles(game.Point{X: 2, Y: 2}, game.Point{X: 3, Y: 0})); err != nil {
+		t.Errorf("Game obstacle error: %s", err)
+	}
+
+	mf := server.NewMakeFood_RandomSeed(&g, 1)
+	seen := map[game.Point]int{}
+	for i := 0; i < 2000; i++ {
+		f, err := mf.NextFood()
+		if err != nil {
+			t.Fatalf("Random food produced an unexpected error: %s", err)
+		}
+		if !g.Free(f) {
+			t.Errorf("Random food was placed on a point that is not free: %s", f)
+		}
+		seen[f]++
+	}
+	if len(seen) != g.FreeCount() {
+		t.Errorf("Random food did not use every free point: %d of %d", len(seen), g.FreeCount())
+	}
+	if seen[game.Point{X: 3, Y: 3}] == 0 {
+		t.Errorf("Random food never used the top right corner of the grid")
+	}
+}
+
+// Test that random food picks different free points for one request, and no more
+// than there are
+func Test_NeedsFoodRandomMany(t *testing.T) {
+	g, _ := game.AutoGame(game.Vector{X: 3, Y: 3}, game.Point{X: 0, Y: 0}) // snake at (1,1)
+	mf := server.NewMakeFood_RandomSeed(&g, 3).(server.MakeFoods)
+
+	for _, n := range []int{5, 20} {
+		ps, err := mf.NextFoods(n)
+		if err != nil {
+			t.Fatalf("Random food produced an unexpected error: %s", err)
+		}
+		seen := map[game.Point]bool{}
+		for _, p := range ps {
+			if seen[p] || !g.Free(p) {
+				t.Errorf("Random food picked a point twice, or one that isn't free: %s", p)
+			}
+			seen[p] = true
+		}
+		want := n
+		if free := g.FreeCount(); want > free {
+			want = free
+		}
+		if len(ps) != want {
+			t.Errorf("Random food picked %d points when %d were asked for", len(ps), n)
+		}
+	}
+}
+
+// Test that seeded random food is reproducible
+func Test_NeedsFoodRandomSeed(t *testing.T) {
+	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 0, Y: 0})
+	a, b := server.NewMakeFood_RandomSeed(&g, 5), server.NewMakeFood_RandomSeed(&g, 5)
+	c := server.NewMakeFood_RandomSource(&g, rand.NewSource(5))
+	d := server.NewMakeFood_RandomSource(&g, rand.NewSource(5))
+
+	for i := 0; i < 20; i++ {
+		af, _ := a.NextFood()
+		bf, _ := b.NextFood()
+		cf, _ := c.NextFood()
+		df, _ := d.NextFood()
+		if !af.Equals(bf) || !cf.Equals(df) {
+			t.Errorf("Random food makers with the same seed made different food")
+		}
+	}
+}
+
+// Test that random food on a full board says so
+func Test_NeedsFoodRandomFull(t *testing.T) {
+	g, err := game.NewGame(game.Grid{X: 1, Y: 0}, game.NewSnake(game.Point{X: 0, Y: 0}, game.Right), game.Point{X: 1, Y: 0})
+	if err != nil {
+		t.Errorf("Game construction error: %s", err)
+	}
+	g.Tick() // eat the last free point
+
+	if f, err := server.NewMakeFood_Random(&g).NextFood(); err != game.ErrBoardFull {
+		t.Errorf("Random food on a full board did not report a full board: %s %v", f, err)
+	}
+}
+
 // Movk for NeedsFood - it just returns whatever point if was filled with
 type NeedsFood_Mock struct {
 	Food game.Point
 }
 
-func (nfm NeedsFood_Mock) NextFood() game.Point {
-	return nfm.Food
+func (nfm NeedsFood_Mock) NextFood() (game.Point, error) {
+	return nfm.Food, nil
+}
+
+// ask a food handler for one item of normal food, and return its point
+func askFood(nfc chan server.FoodRequest) game.Point {
+	req := server.NewFoodRequest(game.NormalFood, 1)
+	nfc <- req
+	f := <-req.Food
+	return f.Point
 }
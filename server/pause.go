@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/james-nesbitt/snake/game"
+	"log"
+)
+
+/**
+ * A running server can be paused, resumed and stepped, with its Pause, Resume and
+ * Step commands:
+ *  1. Pause freezes the game: ticks (from Tick commands or the server clock) are
+ *     dropped until it is resumed
+ *  2. Resume carries on with a paused game, and restarts the clock period so
+ *     that the first tick isn't early
+ *  3. Step ticks a paused game once, and leaves it paused (a running game is
+ *     paused first), so that debugging tools can go one tick at a time
+ *
+ * Turns sent while the game is paused are handled by the PausedTurns policy:
+ * either held until the game resumes or steps (up to maxPausedTurns of them), or
+ * rejected with ErrPaused.  Pausing and resuming go out as events.
+ */
+
+// Control command for a running server
+type Control uint8
+
+const (
+	Pause  Control = iota // freeze the game
+	Resume                // carry on with a paused game
+	Step                  // tick a paused game once
+)
+
+// Convert to a printable string
+func (c Control) String() string {
+	switch c {
+	case Pause:
+		return "pause"
+	case Resume:
+		return "resume"
+	case Step:
+		return "step"
+	default:
+		return "unknown"
+	}
+}
+
+// PausedTurnPolicy what happens to turns while the game is paused
+type PausedTurnPolicy uint8
+
+const (
+	HoldPausedTurns   PausedTurnPolicy = iota // keep the turns until the game resumes or steps
+	RejectPausedTurns                         // reject the turns with ErrPaused
+)
+
+// ErrPaused a turn was rejected because the game is paused
+var ErrPaused = errors.New("Could not turn, the game is paused")
+
+// how many turns are held while the game is paused, after which they are
+// rejected as if the game turn queue was full
+const maxPausedTurns = 16
+
+// Handle a control command, returning true if the game is over
+func (s *Server) control(ctx context.Context, c Control, clock *serverClock) bool {
+	switch c {
+	case Pause:
+		s.pause()
+	case Resume:
+		if !s.paused {
+			return false
+		}
+		s.paused = false
+		log.Printf("RESUMED")
+		s.releaseTurns()
+		clock.restart()
+		s.emit(Event{Type: EventResumed})
+	case Step:
+		s.pause()
+		log.Printf("STEP")
+		s.releaseTurns()
+		return s.tick(ctx)
+	default:
+		log.Printf("CONTROL: Unknown command %d", c)
+	}
+	return false
+}
+
+// pause the game, if it isn't already
+func (s *Server) pause() {
+	if s.paused {
+		return
+	}
+	s.paused = true
+	log.Printf("PAUSED")
+	s.emit(Event{Type: EventPaused})
+}
+
+// hold (or reject) a turn made while the game is paused
+func (s *Server) holdTurn(t snakeTurn) {
+	if s.PausedTurns == RejectPausedTurns {
+		s.rejectTurn(t, ErrPaused)
+		return
+	}
+	if len(s.held) >= maxPausedTurns {
+		te := game.TurnError{Snake: t.snake, To: t.dir, Reason: game.TurnQueueFull}
+		if sn, err := s.Game.Snake(t.snake); err == nil {
+			te.From = sn.Facing()
+		}
+		s.rejectTurn(t, te)
+		return
+	}
+	log.Printf("TURN HELD: [Snake %d] %s", t.snake, t.dir)
+	s.held = append(s.held, t)
+}
+
+// pass any held turns to the game, in the order they were made
+func (s *Server) releaseTurns() {
+	for _, t := range s.held {
+		s.turn(t)
+	}
+	s.held = s.held[:0]
+}
@@ -0,0 +1,82 @@
+package server_test
+
+import (
+	"context"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/server"
+	"testing"
+	"time"
+)
+
+// Test that a paused server drops ticks, holds turns and steps
+func Test_ServerPauseStep(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 0, Y: 0})
+	s := server.NewServer(&g)
+
+	logDeaths(&s, t)
+	sub := s.Subscribe(256)
+	go server.NeedFoodHandler(server.NewMakeFood_Slice(nil), s.NeedsFood, ctx)
+	go s.Start(ctx)
+
+	s.Pause(ctx)
+	waitEvent(t, sub, server.EventPaused)
+	for i := 0; i < 3; i++ {
+		s.Tick(ctx) // dropped
+	}
+	s.Turn(ctx, game.Left) // held until the step
+	s.Step(ctx)
+	s.Step(ctx)
+
+	cancel()
+	for r := range s.Finished {
+		if r.Ticks != 2 {
+			t.Errorf("Paused game ticked the wrong number of times: %d", r.Ticks)
+		}
+	}
+	if g.Head().String() != "(3,5)" || g.Facing() != game.Left {
+		t.Errorf("Held turn was not applied on the step: %s %s", g.Head(), g.Facing())
+	}
+	for e := range sub.C {
+		if e.Type == server.EventTurnRejected {
+			t.Errorf("Held turn was rejected: %s", e)
+		}
+	}
+}
+
+// Test that a paused server can reject turns, and resumes
+func Test_ServerPauseReject(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 0, Y: 0})
+	s := server.NewServer(&g)
+	s.PausedTurns = server.RejectPausedTurns
+
+	logDeaths(&s, t)
+	sub := s.Subscribe(0)
+	go server.NeedFoodHandler(server.NewMakeFood_Slice(nil), s.NeedsFood, ctx)
+	go s.Start(ctx)
+
+	s.Pause(ctx)
+	waitEvent(t, sub, server.EventPaused)
+	s.Turn(ctx, game.Left)
+	if e, _ := waitEvent(t, sub, server.EventTurnRejected); e.Err != server.ErrPaused {
+		t.Errorf("Turn while paused was not rejected: %s", e)
+	}
+	s.Resume(ctx)
+	waitEvent(t, sub, server.EventResumed)
+	s.Tick(ctx)
+
+	cancel()
+	for r := range s.Finished {
+		if r.Ticks != 1 {
+			t.Errorf("Resumed game ticked the wrong number of times: %d", r.Ticks)
+		}
+	}
+	if g.Facing() != game.Up {
+		t.Errorf("Rejected turn was applied: %s", g.Facing())
+	}
+}
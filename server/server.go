@@ -4,61 +4,162 @@ package server
  * A snake server. The component which can be used to handle snake mechanics
  * for a front end, using channels for interaction.
  *
- * The Server is a struct which provides incoming and outgoing channels:
- *   tick : a clock tick in the snake game (incoming)
- *   turn : a snake direction turn event (incoming)
- *   needs-food : new food placement is needed (food was eaten)
- *   collision-boundary : the snake ran into the grid boundary (outgoing)
- *   collision-snake : the snale ran into itself (outgoing)
+ * The Server takes commands through its methods, which never panic, and return
+ * an error once the server has stopped (see commands.go):
+ *   Tick : a clock tick in the snake game
+ *   SwitchClock : switch the server between its own clock and Tick commands
+ *   Turn, TurnSnake : a snake direction turn
+ *   View : read the game safely, from inside of the game loop
+ *   Spectate : a read only snapshot of the game, and the events after it
+ *   Pause, Resume, Step : freeze, carry on with, or step the game
  *
- * The server must be "Start"ed before interacting with the channels, which
- * needs a context that can be used to kill the Server game.
+ * and provides receive-only outgoing channels:
+ *   needs-food : new food placement is needed (food was eaten, or expired)
+ *   finished : the final result for each snake, when the game ends
  *
- * @TODO the Start method has a context which can be used to stop it, so the
- *    Stop method should probably be removed
+ * Everything else that happens in the game (moves, growth, turns, food, deaths,
+ * pauses and clock changes) goes out as typed events, to every Subscription.
+ *
+ * The server must be "Start"ed before it will take commands, with a context
+ * which stops it.  The server also stops by itself when the game is over.
+ * Either way it stops only once, closing its outgoing channels; the incoming
+ * ones are never closed, so nothing can send on a closed channel.
  */
 
 import (
 	"context"
 	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/store"
 	"log"
+	"sync/atomic"
+	"time"
 )
 
 // NewServer server constructor.  Don't forget to Start before using it
 func NewServer(g *game.Game) Server {
-	tk := make(chan int)
-	tn := make(chan game.Vector)
-	nf := make(chan chan game.Point)
-	bc := make(chan error)
-	sc := make(chan error)
+	nf := make(chan FoodRequest)
+	fn := make(chan game.GameResult, len(g.Snakes())) // buffered so that the results never block the server
+
+	return Server{
+		Game:      g,
+		NeedsFood: nf,
+		Finished:  fn,
 
-	return Server{Game: g, Tick: tk, Turn: tn, NeedsFood: nf, BoundaryCollision: bc, SnakeCollision: sc}
+		ticks:     make(chan struct{}),
+		turns:     make(chan snakeTurn),
+		views:     make(chan func(*game.Game)),
+		clocks:    make(chan bool),
+		controls:  make(chan Control),
+		needsFood: nf,
+		finished:  fn,
+		done:      make(chan struct{}),
+		starts:    new(int32),
+		events:    &eventBus{},
+	}
 }
 
 /**
  * A server object which can be used to interact with a game as a backend
- *
- * @TODO chans should be strict about incoming and outgoing.
  */
 type Server struct {
 	Game *game.Game
 
-	// Incoming instructions
-	Tick chan int         // Game tick (step) trigger
-	Turn chan game.Vector // Snake turn trigger
+	// Outgoing requests
+	NeedsFood <-chan FoodRequest // Food is needed (to be sent on the request chan)
+
+	// Outgoing end of game
+	Finished <-chan game.GameResult // the final result of every snake, when the server stops
+
+	// FoodCount how many items of food to keep on the board, 1 if it isn't set.
+	// More food is asked for after any tick that leaves less than this.
+	FoodCount int
+
+	// FoodTimeout how long to wait for an answer to a food request
+	// (DefaultFoodTimeout if it isn't set, and forever if it is negative).
+	// FoodRetries how many times to ask again when food is rejected
+	// (DefaultFoodRetries if it isn't set, and never if it is negative).
+	// FallbackFood makes the food when a request isn't answered in time, or its
+	// food is still rejected, which is random food if it isn't set.
+	FoodTimeout  time.Duration
+	FoodRetries  int
+	FallbackFood MakeFood
+
+	// ChooseFood (optional) chooses the kind and value of the new food, on the
+	// server loop, each time that food is needed (normal food if it isn't set).
+	ChooseFood func(g *game.Game) (game.FoodKind, int)
 
-	// Outgoing info
-	NeedsFood chan chan game.Point // Food was eaten (new food needed on the passed chan)
+	// MakeFood (optional) makes the food on the server loop, where it can safely
+	// read the game, instead of asking for it on NeedsFood.  Set it (or call
+	// RandomFood) before starting the server.
+	MakeFood MakeFood
+
+	// Recorder (optional) records every turn, tick and food placement, so that a
+	// game can be replayed.  Set it before starting the server.
+	Recorder *game.Recorder
+
+	// Store (optional) keeps the final results for high scores, with the players
+	// and level from StoreInfo.  The game duration is timed from Start if it
+	// isn't set.  A game that is stopped before it is over isn't stored.
+	Store     *store.Store
+	StoreInfo store.GameInfo
+
+	// SelfClock starts the server on its own clock, ticking by the ClockRules
+	// (DefaultClockRules if they aren't set).  Set them before starting the server.
+	// Eating food only speeds the game up once the clock has been switched on, or
+	// if the ClockRules are set.
+	SelfClock  bool
+	ClockRules ClockRules
+
+	// PausedTurns what to do with turns while the game is paused, which is to
+	// hold them until it resumes unless it is set.
+	PausedTurns PausedTurnPolicy
+
+	// SpectatorDelay how many ticks behind the game spectators are kept, so that
+	// they can't relay moves to a player (live if it isn't set).  Set it before
+	// starting the server.
+	SpectatorDelay uint
+
+	started  time.Time
+	interval time.Duration // the server clock period, 0 while it is off
+	eaten    int           // how much food has been eaten, for speeding up
+	clocked  bool          // the clock has been switched on, so eating speeds the game up
+	paused   bool          // the game is paused, so ticks are dropped
+	held     []snakeTurn   // turns made while the game was paused
+	events   *eventBus     // the event subscriptions
+
+	ticks     chan struct{}
+	turns     chan snakeTurn
+	views     chan func(*game.Game)
+	clocks    chan bool
+	controls  chan Control
+	needsFood chan FoodRequest
+	finished  chan game.GameResult
+	done      chan struct{} // closed when the server stops
+	starts    *int32        // how many times the server was started
+}
 
-	// Outgoing errors
-	BoundaryCollision chan error
-	SnakeCollision    chan error
+// RandomFood make random food on the server loop, from the game random numbers,
+// unless a MakeFood is already set.  This is the food that most front ends want,
+// without running a NeedFoodHandler.  Call it before starting the server.
+func (s *Server) RandomFood() {
+	if s.MakeFood == nil {
+		s.MakeFood = NewMakeFood_Random(s.Game)
+	}
 }
 
-// Start the server running a game by open all channels and listening on then in
-// a game loop
-func (s *Server) Start(ctx context.Context) {
+// Start the server running a game by listening for commands in a game loop, until
+// the context is done or the game is over.  A server can only be started once.
+func (s *Server) Start(ctx context.Context) error {
+	if atomic.AddInt32(s.starts, 1) > 1 {
+		return ErrStarted
+	}
 	log.Printf("START SNAKE SERVER")
+	s.started = time.Now()
+
+	clock := serverClock{s: s}
+	defer clock.stop()
+	clock.switchTo(s.SelfClock)
+	s.holdFrame()
 
 	/**
 	 * Main event loop
@@ -72,6 +173,10 @@ func (s *Server) Start(ctx context.Context) {
 	 * 1. TICK -> a game clock tick.  Done as a signal so that a  Game consumer can
 	 *            regulate the game
 	 * 2. TURN -> a snake turn even. This can happen at any time
+	 *    CLOCK -> switch the server clock on or off.  While it is on, the server
+	 *            ticks itself as well
+	 *    CONTROL -> pause, resume or step the game.  While it is paused, ticks
+	 *            are dropped and turns are held (or rejected)
 	 * 3. FOOD -> new food placement.  This is EXPECTED to occur only after the
 	 *            outgoing NEEDFOOD signal is sent, but really it could happen
 	 *  @TODO  perhaps the NEEDFOOD chan needs to be reworked to be isolated.
@@ -79,12 +184,13 @@ func (s *Server) Start(ctx context.Context) {
 	 * OUTGOING SIGNALS
 	 *
 	 * 4. NEEDFOOD <- New food is needed.  An external algorithm should be applied
-	 *           which decides where to put food.
+	 *           which decides where to put food, for the kind and count asked for.
+	 * 5. EVENTS <- Everything that happens goes out to the event subscriptions,
+	 *           without ever blocking (see events.go)
 	 *
-	 * ERROR SIGNALS (OUTGOING)
+	 * END SIGNALS (OUTGOING)
 	 *
-	 * 5. BOUNDARY -> The snake has collided with the grid boundary
-	 * 6. SNAKECOLLISION -> The snake has collided with itself
+	 * 6. FINISHED -> The final result of each snake, however the game ended
 	 *
 	 */
 
@@ -93,59 +199,171 @@ func (s *Server) Start(ctx context.Context) {
 		case <-ctx.Done():
 			log.Printf("STOP REQUESTED")
 			s.stop()
-			return
-		case _ = <-s.Tick:
-			res, err := s.Game.Tick()
-
-			if err != nil {
-				log.Printf("TICK: ERROR [Snake: %s]", s.Game.Head())
-				if res.BoundaryCollision {
-					s.BoundaryCollision <- err
-				}
-				if res.SnakeCollision {
-					s.SnakeCollision <- err
-				}
-
+			return nil
+		case <-s.ticks:
+			if s.paused {
+				log.Printf("TICK: Dropped while paused")
+				break
+			}
+			if s.tick(ctx) {
+				s.stop()
+				return nil
+			}
+			clock.update()
+		case <-clock.C:
+			if s.paused {
+				break
+			}
+			if s.tick(ctx) {
+				s.stop()
+				return nil
+			}
+			clock.update()
+		case on := <-s.clocks:
+			clock.switchTo(on)
+		case c := <-s.controls:
+			if s.control(ctx, c, &clock) {
 				s.stop()
+				return nil
+			}
+			clock.update()
+		case t := <-s.turns:
+			if s.paused {
+				s.holdTurn(t)
+				break
+			}
+			s.turn(t)
+		case f := <-s.views:
+			f(s.Game)
+		}
+	}
+}
 
-				return
-			} else if res.Grew {
-				log.Printf("TICK: GREW [Dir: %s][Snake: %s]", s.Game.Facing(), s.Game.Head())
-			} else if res.Moved {
-				if f, err := s.Game.Food(); err != nil {
-					log.Printf("TICK: MOVED [Dir: %s][Food: %s][Snake: %s]", s.Game.Facing(), "NONE", s.Game.Head())
-				} else {
-					log.Printf("TICK: MOVED [Dir: %s][Food: %s][Snake: %s]", s.Game.Facing(), f, s.Game.Head())
-				}
+// a turn for a snake
+type snakeTurn struct {
+	snake game.SnakeID
+	dir   game.Vector
+}
+
+// Turn a snake, and record or report the turn
+func (s *Server) turn(t snakeTurn) {
+	var from game.Vector
+	if sn, err := s.Game.Snake(t.snake); err == nil {
+		from = sn.Facing()
+	}
+	if err := s.Game.TurnSnake(t.snake, t.dir); err != nil {
+		s.rejectTurn(t, err)
+		return
+	}
+	log.Printf("TURNED: [Snake %d] %s -> %s ", t.snake, from, t.dir)
+	if s.Recorder != nil {
+		s.Recorder.Turn(t.snake, t.dir)
+	}
+	s.emit(Event{Type: EventTurned, Snake: t.snake, Dir: t.dir})
+}
+
+// Tick the game, and handle the results, returning true if the game is over (or
+// the context was done while asking for food)
+func (s *Server) tick(ctx context.Context) bool {
+	speed := s.Game.Speed()
+	rs, err := s.Game.Tick()
+	if s.Recorder != nil {
+		s.Recorder.Tick(rs)
+	}
+	s.emit(Event{Type: EventTicked})
+
+	for _, res := range rs {
+		sn, _ := s.Game.Snake(res.Snake)
+		hp := sn.HeadPoint()
+
+		if res.AteFood {
+			s.emit(Event{Type: EventAte, Snake: res.Snake, Point: hp, Food: res.Food})
+		}
+		if res.BoundaryCollision || res.ObstacleCollision || res.SnakeCollision || res.HeadCollision {
+			log.Printf("TICK: ERROR [Snake %d: %s length %d]", res.Snake, hp, sn.Length())
+			gr, _ := s.Game.Result(res.Snake)
+			s.emit(Event{Type: EventDied, Snake: res.Snake, Point: hp, Reason: gr.Reason, Err: err})
+		} else if res.Grew {
+			log.Printf("TICK: GREW [Dir: %s][Snake %d: %s length %d]", sn.Facing(), res.Snake, hp, sn.Length())
+			s.emit(Event{Type: EventGrew, Snake: res.Snake, Point: hp, Points: res.Points})
+		} else if res.Moved {
+			s.emit(Event{Type: EventMoved, Snake: res.Snake, Point: hp, Points: res.Points})
+			if f, err := s.Game.Food(); err != nil {
+				log.Printf("TICK: MOVED [Dir: %s][Food: %s][Snake %d: %s length %d]", sn.Facing(), "NONE", res.Snake, hp, sn.Length())
+			} else {
+				log.Printf("TICK: MOVED [Dir: %s][Food: %s][Snake %d: %s length %d]", sn.Facing(), f, res.Snake, hp, sn.Length())
 			}
+		}
+	}
 
-			if res.AteFood {
-				// originally we played with separation of the NeedsFood and Food chans
-				// but it required validation on the tick level and caused an issue with
-				// closed channels if making food happens after closing the outer context
-
-				log.Printf("ATE: Asking for new food point")
-				fc := make(chan game.Point) // New food chan, to receive a new food point
-				s.NeedsFood <- fc           // send out a signal that we need new food
-				food := <-fc                // receive new food position from the sent chan
-				close(fc)                   // prevent subsequent sends to the chan
-				s.Game.SetFood(food)        // place the food
-				log.Printf("FOOD: New food created at %s", food)
+	// The game is over once it is won, or no snakes are left playing
+	if s.Game.Over() {
+		if s.Game.Won() {
+			log.Printf("VICTORY: the board is full")
+			for _, gr := range s.Game.Results() {
+				if gr.Reason == game.Victory {
+					sn, _ := s.Game.Snake(gr.Snake)
+					s.emit(Event{Type: EventWon, Snake: gr.Snake, Point: sn.HeadPoint(), Reason: gr.Reason})
+				}
 			}
+		}
+		return true
+	}
+
+	s.speedUp(rs)
+	if s.Game.Speed() != speed {
+		s.emit(Event{Type: EventSpeed, Speed: s.Game.Speed()})
+	}
 
-		case dir := <-s.Turn:
-			log.Printf("TURNED: %s -> %s ", s.Game.Facing(), dir)
-			s.Game.Turn(dir)
+	if n := s.foodCount() - len(s.Game.Foods()); n > 0 {
+		// originally we played with separation of the NeedsFood and Food chans
+		// but it required validation on the tick level and caused an issue with
+		// closed channels if making food happens after closing the outer context
+		if !s.needFood(ctx, n) {
+			return true
 		}
 	}
+	s.holdFrame()
+	return false
 }
 
-// Stop the Server
+// how much food to keep on the board
+func (s *Server) foodCount() int {
+	if s.FoodCount < 1 {
+		return 1
+	}
+	return s.FoodCount
+}
+
+// Report a rejected turn
+func (s *Server) rejectTurn(t snakeTurn, err error) {
+	log.Printf("TURN REJECTED: %s", err)
+	s.emit(Event{Type: EventTurnRejected, Snake: t.snake, Dir: t.dir, Err: err})
+}
+
+// Stop the Server, which only happens once, when the game loop ends.  The
+// incoming chans are left open, and commands see the done chan instead.
 func (s *Server) stop() {
-	close(s.Tick)
-	close(s.Turn)
-	close(s.NeedsFood)
-	close(s.BoundaryCollision)
-	close(s.SnakeCollision)
+	close(s.done)
+	close(s.needsFood)
+
+	for _, r := range s.Game.Results() {
+		log.Printf("RESULT: [Snake %d: %s][Score: %d][Length: %d][Ticks: %d][Food: %d]", r.Snake, r.Reason, r.Score, r.Length, r.Ticks, r.FoodEaten)
+		s.finished <- r
+	}
+	if s.Store != nil && s.Game.Over() {
+		gi := s.StoreInfo
+		if gi.Seed == 0 {
+			gi.Seed = s.Game.RandomSeed()
+		}
+		if gi.Duration == 0 {
+			gi.Duration = time.Since(s.started)
+		}
+		if err := s.Store.AddResults(gi, s.Game.Results()); err != nil {
+			log.Printf("STORE: Could not store the results: %s", err)
+		}
+	}
+	s.events.close()
+	close(s.finished)
 	log.Printf("STOPPED SNAKE SERVER")
 }
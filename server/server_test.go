@@ -4,6 +4,9 @@ import (
 	"context"
 	"github.com/james-nesbitt/snake/game"
 	"github.com/james-nesbitt/snake/server"
+	"github.com/james-nesbitt/snake/store"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -26,8 +29,7 @@ func Test_BasicTimedServer(t *testing.T) {
 	}
 	s := server.NewServer(&g)
 
-	go logErrorChan(s.BoundaryCollision, t)
-	go logErrorChan(s.SnakeCollision, t)
+	logDeaths(&s, t)
 
 	// place new food two points ahead of the snake
 	go server.NeedFoodHandler(server.NewMakeFood_Move(&g, game.Vector{Y: 2}), s.NeedsFood, ctx)
@@ -37,7 +39,7 @@ func Test_BasicTimedServer(t *testing.T) {
 	for i := 0; i < 5; i++ {
 		select {
 		case <-ticker.C:
-			s.Tick <- i
+			s.Tick(ctx)
 		case <-ctx.Done():
 			t.Errorf("Safe timeout expired")
 			return
@@ -51,13 +53,13 @@ func Test_BasicTimedServer(t *testing.T) {
 func Test_SnakeWanderServer(t *testing.T) {
 	ticker := time.NewTicker(testTick)
 	defer ticker.Stop()
-	ctx, _ := context.WithTimeout(context.Background(), 20*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
 
 	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 5, Y: 7})
 	s := server.NewServer(&g)
 
-	go logErrorChan(s.BoundaryCollision, t)
-	go logErrorChan(s.SnakeCollision, t)
+	logDeaths(&s, t)
 
 	// Move the food on a planned set of points
 	m := []game.Point{
@@ -73,7 +75,7 @@ func Test_SnakeWanderServer(t *testing.T) {
 	for i := 0; i < 4; i++ {
 		select {
 		case <-ticker.C:
-			s.Tick <- i
+			s.Tick(ctx)
 		case <-ctx.Done():
 			t.Errorf("Safe timeout expired")
 			return
@@ -89,8 +91,7 @@ func Test_SnakePlayAGame(t *testing.T) {
 	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 5, Y: 7})
 	s := server.NewServer(&g)
 
-	go logErrorChan(s.BoundaryCollision, t)
-	go logErrorChan(s.SnakeCollision, t)
+	logDeaths(&s, t)
 
 	// Move the food on a planned set of points
 	m := []game.Point{
@@ -106,47 +107,47 @@ func Test_SnakePlayAGame(t *testing.T) {
 
 	go s.Start(ctx)
 
-	s.Tick <- 1
-	s.Tick <- 1
-	s.Tick <- 1
-	s.Turn <- game.Right
-	s.Tick <- 2
-	s.Tick <- 2
-	s.Turn <- game.Up
-	s.Tick <- 3
-	s.Turn <- game.Left
-	s.Tick <- 4
-	s.Tick <- 4
-	s.Tick <- 4
-	s.Tick <- 4
-	s.Tick <- 4
-	s.Turn <- game.Down
-	s.Tick <- 5
-	s.Tick <- 5
-	s.Tick <- 5
-	s.Tick <- 5
-	s.Tick <- 5
-	s.Tick <- 5
-	s.Tick <- 5
-	s.Tick <- 5
-	s.Tick <- 5
-	s.Turn <- game.Right
-	s.Tick <- 6
-	s.Tick <- 6
-	s.Tick <- 6
-	s.Tick <- 6
-	s.Turn <- game.Up
-	s.Tick <- 7
-	s.Tick <- 7
-	s.Turn <- game.Left
-	s.Tick <- 8
-	s.Tick <- 8
-	s.Tick <- 8
-	s.Tick <- 8
-	s.Tick <- 8
-	s.Turn <- game.Down
-	s.Tick <- 9
-	s.Tick <- 9
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Turn(ctx, game.Right)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Turn(ctx, game.Up)
+	s.Tick(ctx)
+	s.Turn(ctx, game.Left)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Turn(ctx, game.Down)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Turn(ctx, game.Right)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Turn(ctx, game.Up)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Turn(ctx, game.Left)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Turn(ctx, game.Down)
+	s.Tick(ctx)
+	s.Tick(ctx)
 }
 
 // Test Move and Grow and end up in a self-collision
@@ -157,19 +158,7 @@ func Test_SnakeSelfCollide(t *testing.T) {
 	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 5, Y: 6})
 	s := server.NewServer(&g)
 
-	go logErrorChan(s.BoundaryCollision, t)
-
-	// Listen for unexpected errors until we say stop
-	stop := make(chan bool)
-	go func(err chan error, stop chan bool, t *testing.T) {
-		select {
-		case rec := <-err:
-			t.Error("Error received: ", rec)
-		case <-stop:
-			t.Log("Disabling Snake collision catch")
-			return
-		}
-	}(s.SnakeCollision, stop, t)
+	sub := s.Subscribe(256)
 
 	// Move the food on a planned set of points which gives us a fast chance to grow
 	m := []game.Point{
@@ -186,88 +175,354 @@ func Test_SnakeSelfCollide(t *testing.T) {
 
 	go s.Start(ctx)
 
-	s.Tick <- 1
-	s.Tick <- 1
-	s.Tick <- 1
-	s.Tick <- 1
-	s.Tick <- 1
-	s.Turn <- game.Right
-	s.Tick <- 2
-	s.Tick <- 2
-	s.Turn <- game.Down
-	s.Tick <- 3
-	s.Turn <- game.Left
-	s.Tick <- 4
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Turn(ctx, game.Right)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Turn(ctx, game.Down)
+	s.Tick(ctx)
+	s.Turn(ctx, game.Left)
+	s.Tick(ctx)
+	s.Tick(ctx) // Should cause a snake collision
+
+	if e, ok := waitEvent(t, sub, server.EventDied); !ok || e.Reason != game.HitSnake || e.Tick != 10 {
+		t.Errorf("Failed to receive expected snake collision: %s", e)
+	}
+}
+
+// Test some game snake wandering.  Move and Grow
+func Test_SnakeBoundaryCollide(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 5, Y: 6})
+	s := server.NewServer(&g)
+
+	sub := s.Subscribe(256)
+
+	// We won't eat food here, so we can ignore food generation
+	go server.NeedFoodHandler(NeedsFood_Mock{Food: game.Point{X: 1, Y: 2}}, s.NeedsFood, ctx)
+
+	go s.Start(ctx)
+
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Turn(ctx, game.Right)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx) // Should cause a boundary collision
+
+	if e, ok := waitEvent(t, sub, server.EventDied); !ok || e.Reason != game.HitBoundary || e.Err == nil {
+		t.Errorf("Failed to receive expected boundary collision: %s", e)
+	}
+}
 
-	stop <- true // the SnakeBoundary Chan now has no listener
+// Test that filling the board is reported as a win
+func Test_SnakeFillsBoard(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.NewGame(game.Grid{X: 1, Y: 1}, game.NewSnake(game.Point{X: 0, Y: 0}, game.Right), game.Point{X: 1, Y: 0})
+	s := server.NewServer(&g)
+
+	logDeaths(&s, t)
+	go server.NeedFoodHandler(server.NewMakeFood_Slice([]game.Point{{X: 1, Y: 1}, {X: 0, Y: 1}}), s.NeedsFood, ctx)
+	go s.Start(ctx)
 
-	s.Tick <- 4 // Should cause a snake collision
+	s.Tick(ctx) // eat (1,0)
+	s.Turn(ctx, game.Up)
+	s.Tick(ctx) // eat the food at (1,1)
+	s.Turn(ctx, game.Left)
+	s.Tick(ctx) // eat the food at (0,1)
 
-	giveup, _ := context.WithTimeout(context.Background(), 3*time.Second)
 	select {
-	case <-giveup.Done():
-		t.Errorf("Failed to receive expected snake collision error on chan")
-	case err := <-s.SnakeCollision:
-		t.Logf("Received expected error on snake collision error chan : %s", err)
+	case <-ctx.Done():
+		t.Errorf("Failed to receive expected board full win")
+	case r := <-s.Finished:
+		if r.Reason != game.Victory || r.Length != 4 || r.FoodEaten != 3 || r.Ticks != 3 {
+			t.Errorf("Received an unexpected result for a board full win : %+v", r)
+		}
 	}
 }
 
-// Test some game snake wandering.  Move and Grow
-func Test_SnakeBoundaryCollide(t *testing.T) {
+// Test that a rejected turn is reported, and doesn't end the game
+func Test_ServerTurnRejected(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 
 	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 5, Y: 6})
 	s := server.NewServer(&g)
 
-	// Listen for unexpected errors until we say stop
-	stop := make(chan bool)
-	go func(err chan error, stop chan bool, t *testing.T) {
-		select {
-		case rec := <-err:
-			t.Error("Error received: ", rec)
-		case <-stop:
-			t.Log("Disabling Boundary collision catch")
-			return
+	logDeaths(&s, t)
+	sub := s.Subscribe(0)
+	go server.NeedFoodHandler(NeedsFood_Mock{Food: game.Point{X: 1, Y: 2}}, s.NeedsFood, ctx)
+	go s.Start(ctx)
+
+	s.Tick(ctx) // eat, so that the snake has a neck
+	s.Turn(ctx, game.Down)
+
+	if e, ok := waitEvent(t, sub, server.EventTurnRejected); !ok {
+		t.Errorf("Failed to receive expected turn rejection")
+	} else if te, isTurn := e.Err.(game.TurnError); !isTurn || te.Reason != game.TurnReversed || e.Dir != game.Down {
+		t.Errorf("Received an unexpected turn rejection: %s", e)
+	}
+
+	s.Turn(ctx, game.Vector{X: 2, Y: 2})
+	if e, _ := waitEvent(t, sub, server.EventTurnRejected); e.Err == nil {
+		t.Errorf("Did not receive a turn rejection for a bad direction")
+	}
+	s.Tick(ctx)
+}
+
+// Test that a recorded server game replays the same
+func Test_ServerRecording(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 5, Y: 6})
+	s := server.NewServer(&g)
+	r, err := game.NewRecorder(&g)
+	if err != nil {
+		t.Fatalf("Error creating recorder: %s", err)
+	}
+	s.Recorder = r
+
+	go server.NeedFoodHandler(server.NewMakeFood_Slice([]game.Point{{X: 8, Y: 8}}), s.NeedsFood, ctx)
+	go s.Start(ctx)
+
+	s.Tick(ctx) // eat
+	s.Turn(ctx, game.Right)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	s.Tick(ctx) // (10,6)
+	s.Tick(ctx) // boundary collision
+
+	// the server is done with the recorder once it has sent its results
+	if r, ok := <-s.Finished; !ok || r.Reason != game.HitBoundary || r.Ticks != 6 || r.FoodEaten != 1 {
+		t.Errorf("Did not receive the expected result for a boundary collision: %+v", r)
+	}
+
+	_, trs, err := r.Replay().Play()
+	if err != nil {
+		t.Errorf("Error replaying recorded game: %s", err)
+	}
+	if len(trs) != 7 {
+		t.Errorf("Replay had the wrong number of ticks: %d", len(trs))
+	} else if res, _ := trs[6].Get(0); !res.BoundaryCollision {
+		t.Errorf("Replay did not end in a boundary collision: %+v", res)
+	}
+}
+
+// Test that the server keeps asking for food until it has its food count
+func Test_ServerFoodCount(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 5, Y: 6})
+	s := server.NewServer(&g)
+	s.FoodCount = 3
+	r, err := game.NewRecorder(&g)
+	if err != nil {
+		t.Fatalf("Error creating recorder: %s", err)
+	}
+	s.Recorder = r
+
+	logDeaths(&s, t)
+	// the food handler stops when the server closes its chan, as the server still
+	// needs food after it is cancelled
+	go server.NeedFoodHandler(server.NewMakeFood_Slice([]game.Point{{X: 1, Y: 1}, {X: 2, Y: 2}}), s.NeedsFood, context.Background())
+	go s.Start(ctx)
+
+	s.Tick(ctx) // eat, and ask for 3 food but only get 2
+	s.Tick(ctx) // ask for 1 food but get none
+	cancel()
+
+	// the server is done with the recorder once it has sent its results
+	for range s.Finished {
+	}
+	fs := []game.Food{}
+	for _, e := range r.Replay().Events {
+		if e.Action == game.ReplayFood {
+			fs = append(fs, e.Food)
 		}
-	}(s.BoundaryCollision, stop, t)
+	}
+	if len(fs) != 2 || fs[0] != game.NewFood(game.Point{X: 1, Y: 1}) || fs[1] != game.NewFood(game.Point{X: 2, Y: 2}) {
+		t.Errorf("Server placed the wrong food: %v", fs)
+	}
+}
 
-	go logErrorChan(s.SnakeCollision, t)
+// Test that the server stores the final results
+func Test_ServerStore(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
 
-	// We won't eat food here, so we can ignore food generation
-	go server.NeedFoodHandler(NeedsFood_Mock{Food: game.Point{X: 1, Y: 2}}, s.NeedsFood, ctx)
+	st, err := store.Open(filepath.Join(t.TempDir(), "scores.jsonl"))
+	if err != nil {
+		t.Fatalf("Error opening store: %s", err)
+	}
+	g, _ := game.AutoGame(game.Vector{X: 2, Y: 2}, game.Point{X: 0, Y: 0})
+	s := server.NewServer(&g)
+	s.Store = st
+	s.StoreInfo = store.GameInfo{Level: "tiny", Players: []string{"ann"}}
 
+	go server.NeedFoodHandler(server.NewMakeFood_Slice(nil), s.NeedsFood, ctx)
 	go s.Start(ctx)
 
-	s.Tick <- 1
-	s.Tick <- 1
-	s.Tick <- 1
-	s.Tick <- 1
-	s.Tick <- 1
-	s.Turn <- game.Right
-	s.Tick <- 2
-	s.Tick <- 2
-	s.Tick <- 2
-	s.Tick <- 2
-	s.Tick <- 2
+	s.Tick(ctx) // (1,2)
+	s.Tick(ctx) // boundary collision
 
-	stop <- true // the SnakeBoundary Chan now has no listener
+	// the results are stored before the finished chan is closed
+	for range s.Finished {
+	}
+	if top, _ := st.Top("tiny", 1); len(top) != 1 || top[0].Player != "ann" || top[0].Reason != game.HitBoundary || top[0].Ticks != 1 || top[0].Seed != g.RandomSeed() {
+		t.Errorf("Server stored the wrong results: %+v", top)
+	}
+}
 
-	s.Tick <- 4 // Should cause a snake collision
+// Test that a game which is stopped before it is over isn't stored
+func Test_ServerStoreStopped(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
 
-	giveup, _ := context.WithTimeout(context.Background(), 3*time.Second)
-	select {
-	case <-giveup.Done():
-		t.Errorf("Failed to receive expected boundary collision error on chan")
-	case err := <-s.BoundaryCollision:
-		t.Logf("Received expected error on boundary collision error chan : %s", err)
+	st, err := store.Open(filepath.Join(t.TempDir(), "scores.jsonl"))
+	if err != nil {
+		t.Fatalf("Error opening store: %s", err)
+	}
+	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 0, Y: 0})
+	s := server.NewServer(&g)
+	s.Store = st
+	s.StoreInfo = store.GameInfo{Level: "tiny", Players: []string{"ann"}}
+	s.RandomFood()
+
+	sctx, stop := context.WithCancel(ctx)
+	go s.Start(sctx)
+	s.Tick(ctx)
+	stop()
+
+	for range s.Finished {
+	}
+	if top, _ := st.Top("tiny", 10); len(top) != 0 {
+		t.Errorf("Server stored the results of a game that wasn't over: %+v", top)
 	}
 }
 
-// Just log errors if they come in - these should be unexpected errors that you
-// don't want to catch yourself
-func logErrorChan(err chan error, t *testing.T) {
-	if rec := <-err; rec != nil {
-		t.Error("Error received: ", rec)
+// Test that every subscriber gets the same ordered stream of events
+func Test_ServerEvents(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 6, Y: 6})
+	s := server.NewServer(&g)
+	a, b := s.Subscribe(0), s.Subscribe(0)
+
+	go server.NeedFoodHandler(server.NewMakeFood_Slice([]game.Point{{X: 8, Y: 8}}), s.NeedsFood, ctx)
+	go s.Start(ctx)
+
+	s.Tick(ctx) // (5,6)
+	s.Turn(ctx, game.Right)
+	s.Tick(ctx) // eat (6,6)
+	s.Pause(ctx)
+	s.Pause(ctx) // already paused, but now the first pause is done
+	b.Unsubscribe()
+	s.Resume(ctx)
+	cancel()
+	for range s.Finished {
+	}
+
+	want := []string{
+		"#1 [tick 1] ticked",
+		"#2 [tick 1] moved [Snake 0: (5,6)]",
+		"#3 [tick 1] turned [Snake 0: (1,0)]",
+		"#4 [tick 2] ticked",
+		"#5 [tick 2] ate [normal food at (6,6)]",
+		"#6 [tick 2] grew [Snake 0: (6,6)]",
+		"#7 [tick 2] food-placed [normal food at (8,8)]",
+		"#8 [tick 2] paused",
+		"#9 [tick 2] resumed",
+	}
+	es := []string{}
+	for e := range a.C {
+		es = append(es, e.String())
+	}
+	if strings.Join(es, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Received the wrong events:\n%s", strings.Join(es, "\n"))
+	}
+	n := 0
+	for range b.C {
+		n++
+	}
+	if n != 8 || a.Dropped() != 0 {
+		t.Errorf("Unsubscribed feed received the wrong events: %d", n)
+	}
+	if _, ok := <-s.Subscribe(0).C; ok {
+		t.Errorf("Subscribing to a stopped server did not give a closed feed")
+	}
+}
+
+// Test that a full feed drops events, without blocking the server
+func Test_ServerEventsDropped(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 0, Y: 0})
+	s := server.NewServer(&g)
+	sub := s.Subscribe(2)
+
+	go server.NeedFoodHandler(server.NewMakeFood_Slice(nil), s.NeedsFood, ctx)
+	go s.Start(ctx)
+
+	for i := 0; i < 3; i++ {
+		s.Tick(ctx)
+	}
+	cancel()
+	for range s.Finished {
+	}
+
+	if e := <-sub.C; e.Seq != 1 || sub.Dropped() != 4 {
+		t.Errorf("Full feed did not drop the later events: %s [dropped %d]", e, sub.Dropped())
+	}
+}
+
+// Just log snake deaths if they happen - these should be unexpected deaths that
+// you don't want to catch yourself
+func logDeaths(s *server.Server, t *testing.T) {
+	sub := s.Subscribe(0)
+	go func() {
+		for e := range sub.C {
+			if e.Type == server.EventDied {
+				t.Error("Snake died: ", e)
+			}
+		}
+	}()
+}
+
+// wait for an event of a type, skipping any others
+func waitEvent(t *testing.T, sub *server.Subscription, et server.EventType) (server.Event, bool) {
+	giveup := time.After(3 * time.Second)
+	for {
+		select {
+		case <-giveup:
+			t.Errorf("Did not receive an expected %s event", et)
+			return server.Event{}, false
+		case e, ok := <-sub.C:
+			if !ok {
+				return server.Event{}, false
+			}
+			if e.Type == et {
+				return e, true
+			}
+		}
 	}
 }
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"github.com/james-nesbitt/snake/game"
+	"log"
+	"time"
+)
+
+/**
+ * Spectators watch a running game, read only, and can join at any time.
+ *
+ * A new spectator gets a full snapshot of the game (its own copy, which it can
+ * read whenever it likes), and then every event that follows the snapshot on its
+ * feed, so that it can keep its copy in step or just draw the events.
+ *
+ * With a SpectatorDelay, spectators are kept that many ticks behind the game, so
+ * that in competitive play they can't relay moves to a player.  The server holds
+ * a snapshot at the end of each of the last SpectatorDelay ticks, along with the
+ * events after it: a new spectator starts from the oldest snapshot, and the
+ * events are let out a tick at a time as the game moves on.  Once the server
+ * stops, everything that was held back is let out before the feeds are closed.
+ *
+ * Only spectators are delayed: a Subscription is always live, and is meant for
+ * the players (and trusted consumers, like a recorder).  Anything that is shown
+ * to someone who isn't playing should come from Spectate.
+ *
+ * The server clock isn't part of the game, so the snapshot also has the clock
+ * period at the time it was taken.
+ */
+
+// Spectator a read only view of the game: a snapshot, and the events after it
+type Spectator struct {
+	*Subscription
+
+	Game     *game.Game    // a copy of the game, as it was when the snapshot was taken
+	Tick     uint          // the game tick of the snapshot
+	Seq      uint64        // the last event before the snapshot, so the feed starts after it
+	Interval time.Duration // the server clock period at the snapshot (0 if the clock was off)
+}
+
+// a held back snapshot, at the end of a tick, and the events after it
+type spectatorFrame struct {
+	snapshot []byte // the binary game snapshot
+	tick     uint
+	seq      uint64
+	interval time.Duration
+	events   []Event
+}
+
+// Spectate the game, with a feed that buffers up to buffer events
+// (DefaultEventBuffer if it is less than 1).  The snapshot is taken on the server
+// loop, so this waits for the server to be started.  Spectating a stopped server
+// gives the final game, and a closed feed.
+func (s *Server) Spectate(ctx context.Context, buffer int) (*Spectator, error) {
+	if buffer < 1 {
+		buffer = DefaultEventBuffer
+	}
+	var sp *Spectator
+	var err error
+	if _, verr := s.ViewFinal(ctx, func(g *game.Game) { sp, err = s.spectate(g, buffer) }); verr != nil {
+		return nil, verr
+	}
+	return sp, err
+}
+
+// take a snapshot, and subscribe a spectator from it.  This runs on the server
+// loop (or once the server has stopped), so no events can come in between.
+func (s *Server) spectate(g *game.Game, buffer int) (*Spectator, error) {
+	c := make(chan Event, buffer)
+	sub := &Subscription{C: c, c: c, bus: s.events}
+	sp := &Spectator{Subscription: sub}
+
+	b := s.events
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var snap []byte
+	if len(b.frames) > 0 {
+		f := b.frames[0]
+		snap, sp.Tick, sp.Seq, sp.Interval = f.snapshot, f.tick, f.seq, f.interval
+		sub.delayed = true
+	} else {
+		var err error
+		if snap, err = g.MarshalBinary(); err != nil {
+			return nil, err
+		}
+		sp.Tick, sp.Seq, sp.Interval = g.Ticks(), b.seq, s.interval
+	}
+
+	sg := game.Game{}
+	if err := sg.UnmarshalBinary(snap); err != nil {
+		return nil, err
+	}
+	sp.Game = &sg
+
+	if b.closed {
+		close(c)
+	} else {
+		b.subs = append(b.subs, sub)
+	}
+	return sp, nil
+}
+
+// hold a snapshot of the game for delayed spectators, at the end of a tick, and
+// let out the events of any tick that is now far enough behind
+func (s *Server) holdFrame() {
+	if s.SpectatorDelay == 0 {
+		return
+	}
+	snap, err := s.Game.MarshalBinary()
+	if err != nil {
+		log.Printf("SPECTATE: Could not take a snapshot: %s", err)
+		return
+	}
+
+	b := s.events
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.frames = append(b.frames, spectatorFrame{snapshot: snap, tick: s.Game.Ticks(), seq: b.seq, interval: s.interval})
+	for uint(len(b.frames)) > s.SpectatorDelay+1 {
+		b.release(b.frames[0].events)
+		b.frames = b.frames[1:]
+	}
+}
+
+// send held back events to the delayed spectators.  The bus must be locked.
+func (b *eventBus) release(es []Event) {
+	for _, sub := range b.subs {
+		if !sub.delayed {
+			continue
+		}
+		for _, e := range es {
+			select {
+			case sub.c <- e:
+			default:
+				sub.dropped++
+			}
+		}
+	}
+}
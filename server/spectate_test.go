@@ -0,0 +1,159 @@
+package server_test
+
+import (
+	"context"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/server"
+	"testing"
+	"time"
+)
+
+// the events waiting on a feed, without waiting for more
+func waiting(sub *server.Subscription) []server.Event {
+	es := []server.Event{}
+	for {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				return es
+			}
+			es = append(es, e)
+		default:
+			return es
+		}
+	}
+}
+
+// Test that a live spectator gets a snapshot, and the events after it
+func Test_ServerSpectate(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 0, Y: 0})
+	s := server.NewServer(&g)
+
+	wctx, wcancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer wcancel()
+	if _, err := s.Spectate(wctx, 0); err != context.DeadlineExceeded {
+		t.Errorf("Spectate did not wait for the server to start: %v", err)
+	}
+
+	go server.NeedFoodHandler(server.NewMakeFood_Slice(nil), s.NeedsFood, ctx)
+	go s.Start(ctx)
+
+	s.Tick(ctx)
+	s.Tick(ctx)
+	sp, err := s.Spectate(ctx, 0)
+	if err != nil {
+		t.Fatalf("Could not spectate: %s", err)
+	}
+	if sp.Tick != 2 || sp.Seq != 4 || !sp.Game.HeadPoint().Equals(game.Point{X: 5, Y: 7}) {
+		t.Errorf("Spectator got the wrong snapshot: [tick %d][seq %d] %s", sp.Tick, sp.Seq, sp.Game.HeadPoint())
+	}
+
+	s.Tick(ctx)
+	if e := <-sp.C; e.Seq != 5 || e.Type != server.EventTicked || e.Tick != 3 {
+		t.Errorf("Spectator feed did not follow the snapshot: %s", e)
+	}
+	if e := <-sp.C; e.Type != server.EventMoved || !e.Point.Equals(game.Point{X: 5, Y: 8}) {
+		t.Errorf("Spectator got the wrong move: %s", e)
+	}
+	if sp.Game.Ticks() != 2 || g.Ticks() == 2 {
+		t.Errorf("Spectator snapshot is not a copy of the game")
+	}
+
+	sp.Unsubscribe()
+	if _, ok := <-sp.C; ok {
+		t.Errorf("Spectator feed was not closed")
+	}
+}
+
+// Test that delayed spectators are kept behind the game, until it is over
+func Test_ServerSpectateDelay(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 0, Y: 0})
+	s := server.NewServer(&g)
+	s.SpectatorDelay = 2
+	live := s.Subscribe(0)
+
+	go server.NeedFoodHandler(server.NewMakeFood_Slice(nil), s.NeedsFood, ctx)
+	go s.Start(ctx)
+
+	for i := 0; i < 3; i++ {
+		s.Tick(ctx)
+	}
+	sp, err := s.Spectate(ctx, 0)
+	if err != nil {
+		t.Fatalf("Could not spectate: %s", err)
+	}
+	if sp.Tick != 1 || sp.Seq != 2 || !sp.Game.HeadPoint().Equals(game.Point{X: 5, Y: 6}) {
+		t.Errorf("Spectator got the wrong snapshot: [tick %d][seq %d] %s", sp.Tick, sp.Seq, sp.Game.HeadPoint())
+	}
+	if es := waiting(sp.Subscription); len(es) != 0 {
+		t.Errorf("Spectator got events that aren't far enough behind: %v", es)
+	}
+	if es := waiting(live); len(es) != 6 {
+		t.Errorf("Live feed was held back: %v", es)
+	}
+
+	s.Tick(ctx)
+	s.View(ctx, func(*game.Game) {}) // the tick is done
+	es := waiting(sp.Subscription)
+	if len(es) != 2 || es[0].Seq != 3 || es[0].Tick != 2 || es[1].Tick != 2 {
+		t.Errorf("Spectator did not get the events of the tick that is far enough behind: %v", es)
+	}
+
+	// once the game is over, everything that was held back is let out
+	s.Tick(ctx)
+	s.Tick(ctx)
+	for range s.Finished {
+	}
+	es = []server.Event{}
+	for e := range sp.C {
+		es = append(es, e)
+	}
+	if len(es) != 8 || es[0].Seq != 5 || es[7].Type != server.EventDied || sp.Dropped() != 0 {
+		t.Errorf("Spectator did not get the rest of the game: %v", es)
+	}
+
+	end, err := s.Spectate(ctx, 0)
+	if err != nil || !end.Game.Over() {
+		t.Fatalf("Could not spectate the finished game: %s", err)
+	}
+	if _, ok := <-end.C; ok {
+		t.Errorf("Spectating a stopped server did not give a closed feed")
+	}
+}
+
+// Test that spectators get the server clock period with their snapshot, even
+// when it was set before the first held back tick
+func Test_ServerSpectateClock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, delay := range []uint{0, 2} {
+		g, _ := game.AutoGame(game.Vector{X: 10, Y: 10}, game.Point{X: 0, Y: 0})
+		s := server.NewServer(&g)
+		s.SpectatorDelay = delay
+		s.SelfClock = true
+		s.ClockRules = server.ClockRules{Period: time.Hour} // never ticks by itself
+		sctx, stop := context.WithCancel(ctx)
+
+		go server.NeedFoodHandler(server.NewMakeFood_Slice(nil), s.NeedsFood, sctx)
+		go s.Start(sctx)
+		s.Tick(ctx)
+
+		sp, err := s.Spectate(ctx, 0)
+		if err != nil {
+			t.Fatalf("Could not spectate: %s", err)
+		}
+		if sp.Interval != time.Hour {
+			t.Errorf("Spectator with a delay of %d got the wrong clock: %s", delay, sp.Interval)
+		}
+		stop()
+		for range s.Finished {
+		}
+	}
+}
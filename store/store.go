@@ -0,0 +1,286 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/james-nesbitt/snake/game"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+/**
+ * A Store keeps the results of finished games in a local file, so that high
+ * scores and player statistics outlive the games themselves.
+ *
+ * The file is a log of JSON records, one per line, which is only ever appended
+ * to.  Every write is a single append of a whole line, so more than one process
+ * (like the screen UI and a network server) can share a store file: each Store
+ * reads in any records that others have appended before it answers a query.
+ *
+ * Lines that can't be read (like a partly written line from a crash) are
+ * skipped, so that one bad record never loses the rest.
+ */
+
+// Record a finished game, for one player
+type Record struct {
+	Player   string         `json:"player"`
+	Level    string         `json:"level"`            // the level name, or "" for a default game
+	Score    int            `json:"score"`            // the final score
+	Length   uint           `json:"length"`           // the final snake length
+	Ticks    uint           `json:"ticks"`            // how many ticks the snake survived
+	Food     uint           `json:"food"`             // how much food the snake ate
+	Reason   game.EndReason `json:"reason"`           // how the game ended
+	Duration time.Duration  `json:"duration"`         // how long the game was played for
+	Seed     int64          `json:"seed"`             // the game random seed
+	Replay   string         `json:"replay,omitempty"` // a reference to a saved replay, like a file path
+	Played   time.Time      `json:"played"`           // when the game finished
+}
+
+// NewRecord a record from a snake's game result
+func NewRecord(player, level string, r game.GameResult) Record {
+	return Record{
+		Player: player,
+		Level:  level,
+		Score:  r.Score,
+		Length: r.Length,
+		Ticks:  r.Ticks,
+		Food:   r.FoodEaten,
+		Reason: r.Reason,
+		Played: time.Now(),
+	}
+}
+
+// Store of finished game records, backed by a file
+type Store struct {
+	path    string
+	mu      sync.Mutex
+	records []Record
+	offset  int64 // how much of the file has been read
+	skipped int   // how many lines could not be read
+}
+
+// Open a store file, creating it if it doesn't exist
+func Open(path string) (*Store, error) {
+	f, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+
+	s := &Store{path: path}
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Add a record to the store
+func (s *Store) Add(r Record) error {
+	if r.Player == "" {
+		return errors.New("Could not add record, it has no player")
+	}
+	if r.Played.IsZero() {
+		r.Played = time.Now()
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return s.refreshLocked()
+}
+
+// read in any records appended since the last read
+func (s *Store) refresh() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refreshLocked()
+}
+
+func (s *Store) refreshLocked() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Seek(s.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	br := bufio.NewReader(f)
+	for {
+		l, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			// a line without a newline is still being written, so leave it for later
+			return nil
+		} else if err != nil {
+			return err
+		}
+		s.offset += int64(len(l))
+
+		l = bytes.TrimSpace(l)
+		if len(l) == 0 {
+			continue
+		}
+		r := Record{}
+		if err := json.Unmarshal(l, &r); err != nil {
+			s.skipped++
+			continue
+		}
+		s.records = append(s.records, r)
+	}
+}
+
+// Skipped how many lines of the store file could not be read
+func (s *Store) Skipped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.skipped
+}
+
+// All of the records, in the order that they were added
+func (s *Store) All() ([]Record, error) {
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Record{}, s.records...), nil
+}
+
+// Top the n best records for a level, highest score first.  Ties go to the
+// earliest game.
+func (s *Store) Top(level string, n int) ([]Record, error) {
+	rs, err := s.All()
+	if err != nil {
+		return nil, err
+	}
+
+	top := []Record{}
+	for _, r := range rs {
+		if r.Level == level {
+			top = append(top, r)
+		}
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].Score > top[j].Score
+	})
+	if n >= 0 && len(top) > n {
+		top = top[:n]
+	}
+	return top, nil
+}
+
+// Stats for a player, across all of their games
+type Stats struct {
+	Player   string        `json:"player"`
+	Games    int           `json:"games"`
+	Wins     int           `json:"wins"`     // games where the board was filled
+	Best     int           `json:"best"`     // the best score
+	Total    int           `json:"total"`    // all of the scores added up
+	Average  float64       `json:"average"`  // the average score
+	Longest  uint          `json:"longest"`  // the longest snake
+	Food     uint          `json:"food"`     // all of the food eaten
+	Ticks    uint          `json:"ticks"`    // all of the ticks survived
+	PlayTime time.Duration `json:"playTime"` // how long all of the games took
+	Levels   []string      `json:"levels"`   // the levels played, in the order first played
+}
+
+// PlayerStats statistics for a player, which are empty if they haven't played
+func (s *Store) PlayerStats(player string) (Stats, error) {
+	rs, err := s.All()
+	if err != nil {
+		return Stats{}, err
+	}
+
+	st := Stats{Player: player, Levels: []string{}}
+	levels := map[string]bool{}
+	for _, r := range rs {
+		if r.Player != player {
+			continue
+		}
+		if st.Games == 0 || r.Score > st.Best {
+			st.Best = r.Score
+		}
+		st.Games++
+		if r.Reason == game.Victory {
+			st.Wins++
+		}
+		st.Total += r.Score
+		if r.Length > st.Longest {
+			st.Longest = r.Length
+		}
+		st.Food += r.Food
+		st.Ticks += r.Ticks
+		st.PlayTime += r.Duration
+		if !levels[r.Level] {
+			levels[r.Level] = true
+			st.Levels = append(st.Levels, r.Level)
+		}
+	}
+	if st.Games > 0 {
+		st.Average = float64(st.Total) / float64(st.Games)
+	}
+	return st, nil
+}
+
+// Players everyone who has a record, in the order that they first played
+func (s *Store) Players() ([]string, error) {
+	rs, err := s.All()
+	if err != nil {
+		return nil, err
+	}
+	ps := []string{}
+	seen := map[string]bool{}
+	for _, r := range rs {
+		if !seen[r.Player] {
+			seen[r.Player] = true
+			ps = append(ps, r.Player)
+		}
+	}
+	return ps, nil
+}
+
+// GameInfo about a finished game, which is the same for every snake in it
+type GameInfo struct {
+	Level    string
+	Players  []string // the player for each snake, by SnakeID
+	Seed     int64
+	Duration time.Duration
+	Replay   string
+}
+
+// AddResults record the results of a finished game, for every snake that has a
+// player
+func (s *Store) AddResults(gi GameInfo, rs []game.GameResult) error {
+	for _, r := range rs {
+		if int(r.Snake) >= len(gi.Players) || gi.Players[r.Snake] == "" {
+			continue
+		}
+		rec := NewRecord(gi.Players[r.Snake], gi.Level, r)
+		rec.Seed, rec.Duration, rec.Replay = gi.Seed, gi.Duration, gi.Replay
+		if err := s.Add(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package store_test
+
+import (
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/store"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// open a new store in a temporary directory
+func tempStore(t *testing.T) (*store.Store, string) {
+	p := filepath.Join(t.TempDir(), "scores.jsonl")
+	s, err := store.Open(p)
+	if err != nil {
+		t.Fatalf("Error opening store: %s", err)
+	}
+	return s, p
+}
+
+// add some records to a store
+func addRecords(t *testing.T, s *store.Store, rs ...store.Record) {
+	for _, r := range rs {
+		if err := s.Add(r); err != nil {
+			t.Fatalf("Error adding record: %s", err)
+		}
+	}
+}
+
+// Test the top scores for a level
+func Test_StoreTop(t *testing.T) {
+	s, _ := tempStore(t)
+	addRecords(t, s,
+		store.Record{Player: "ann", Level: "box", Score: 30},
+		store.Record{Player: "bob", Level: "box", Score: 50},
+		store.Record{Player: "cat", Level: "open", Score: 90},
+		store.Record{Player: "dan", Level: "box", Score: 30},
+		store.Record{Player: "ann", Level: "box", Score: 10},
+	)
+
+	top, err := s.Top("box", 3)
+	if err != nil {
+		t.Fatalf("Error getting top scores: %s", err)
+	}
+	if len(top) != 3 || top[0].Player != "bob" || top[1].Player != "ann" || top[2].Player != "dan" {
+		t.Errorf("Store had the wrong top scores: %+v", top)
+	}
+	if top, _ := s.Top("maze", 3); len(top) != 0 {
+		t.Errorf("Store had top scores for a level that wasn't played: %+v", top)
+	}
+	if err := s.Add(store.Record{Score: 10}); err == nil {
+		t.Errorf("Store accepted a record without a player")
+	}
+}
+
+// Test player statistics
+func Test_StorePlayerStats(t *testing.T) {
+	s, _ := tempStore(t)
+	r := store.NewRecord("ann", "box", game.GameResult{Reason: game.Victory, Length: 12, Ticks: 40, FoodEaten: 11, Score: 110})
+	r.Duration = 20 * time.Second
+	addRecords(t, s, r,
+		store.Record{Player: "ann", Level: "open", Score: 20, Length: 3, Ticks: 10, Food: 2, Duration: 5 * time.Second},
+		store.Record{Player: "bob", Level: "box", Score: 500},
+	)
+
+	st, err := s.PlayerStats("ann")
+	if err != nil {
+		t.Fatalf("Error getting player stats: %s", err)
+	}
+	if st.Games != 2 || st.Wins != 1 || st.Best != 110 || st.Total != 130 || st.Average != 65 || st.Longest != 12 || st.Food != 13 || st.Ticks != 50 || st.PlayTime != 25*time.Second {
+		t.Errorf("Player has the wrong stats: %+v", st)
+	}
+	if len(st.Levels) != 2 || st.Levels[0] != "box" || st.Levels[1] != "open" {
+		t.Errorf("Player has the wrong levels: %v", st.Levels)
+	}
+	if st, _ := s.PlayerStats("cat"); st.Games != 0 {
+		t.Errorf("Player with no games has stats: %+v", st)
+	}
+	if ps, _ := s.Players(); len(ps) != 2 {
+		t.Errorf("Store has the wrong players: %v", ps)
+	}
+}
+
+// Test that two stores can share a file, and that it survives bad lines
+func Test_StoreShared(t *testing.T) {
+	a, p := tempStore(t)
+	b, err := store.Open(p)
+	if err != nil {
+		t.Fatalf("Error opening store again: %s", err)
+	}
+
+	addRecords(t, a, store.Record{Player: "ann", Score: 10})
+	addRecords(t, b, store.Record{Player: "bob", Score: 20})
+
+	// a crash in the middle of a write, and then another record
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("Error opening store file: %s", err)
+	}
+	f.WriteString("{\"player\":\"cr\n")
+	f.Close()
+	addRecords(t, a, store.Record{Player: "cat", Score: 30})
+
+	for _, s := range []*store.Store{a, b} {
+		if top, _ := s.Top("", 10); len(top) != 3 || top[0].Player != "cat" {
+			t.Errorf("Store doesn't have every record: %+v", top)
+		}
+	}
+
+	c, err := store.Open(p)
+	if err != nil {
+		t.Fatalf("Error reopening store: %s", err)
+	}
+	if rs, _ := c.All(); len(rs) != 3 || c.Skipped() != 1 {
+		t.Errorf("Reopened store has the wrong records: %+v (%d skipped)", rs, c.Skipped())
+	}
+}
+
+// Test storing the results of a game, for the snakes that have players
+func Test_StoreAddResults(t *testing.T) {
+	s, _ := tempStore(t)
+	rs := []game.GameResult{
+		{Snake: 0, Reason: game.HitSnake, Score: 40},
+		{Snake: 1, Reason: game.HitHead, Score: 70},
+		{Snake: 2, Reason: game.HitBoundary, Score: 10},
+	}
+	if err := s.AddResults(store.GameInfo{Level: "duel", Players: []string{"ann", "bob"}, Seed: 7, Duration: time.Minute, Replay: "duel.json"}, rs); err != nil {
+		t.Fatalf("Error adding results: %s", err)
+	}
+
+	top, _ := s.Top("duel", -1)
+	if len(top) != 2 || top[0].Player != "bob" || top[0].Score != 70 || top[0].Reason != game.HitHead || top[0].Seed != 7 || top[0].Duration != time.Minute || top[0].Replay != "duel.json" {
+		t.Errorf("Store has the wrong records for the results: %+v", top)
+	}
+}
@@ -0,0 +1,123 @@
+package tcp
+
+import (
+	"context"
+	"github.com/james-nesbitt/snake/game"
+	"log"
+	"net"
+	"sync"
+)
+
+/**
+ * A tcp Host serves snake games over a line based text protocol, so that bots
+ * written in any language (or a person with telnet) can play.
+ *
+ * Every connection is a session, with its own game.Game and server.Server.  The
+ * server has no clock of its own: the game only moves when the session sends a
+ * TICK, so a bot can take as long as it likes to think.  The protocol is in the
+ * package README.
+ */
+
+// Config for the games that a host creates
+type Config struct {
+	Grid      game.Vector // the largest X and Y on the grid (19,19 if it isn't set)
+	FoodCount int         // how much food to keep on the board
+	Level     string      // (optional) a level file that every session plays, instead of an empty grid
+}
+
+// the default grid for tcp games
+var defaultGrid = game.Vector{X: 19, Y: 19}
+
+// Host of tcp game sessions
+type Host struct {
+	cfg Config
+
+	mu       sync.Mutex
+	sessions int
+}
+
+// NewHost a host for tcp game sessions
+func NewHost(cfg Config) *Host {
+	if cfg.Grid.X < 1 || cfg.Grid.Y < 1 {
+		cfg.Grid = defaultGrid
+	}
+	return &Host{cfg: cfg}
+}
+
+// ListenAndServe listen on a TCP address, and serve sessions on it until the
+// context is done
+func (h *Host) ListenAndServe(ctx context.Context, addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return h.Serve(ctx, l)
+}
+
+// Serve a session for every connection on a listener, until the context is done.
+// The listener is closed, and every session is ended before Serve returns.
+func (h *Host) Serve(ctx context.Context, l net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.serve(ctx, c)
+		}()
+	}
+}
+
+// Sessions how many sessions are connected
+func (h *Host) Sessions() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sessions
+}
+
+// serve one connection, until it quits or the context is done
+func (h *Host) serve(ctx context.Context, c net.Conn) {
+	h.mu.Lock()
+	h.sessions++
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		h.sessions--
+		h.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		// unblock the session read when the host stops
+		<-ctx.Done()
+		c.Close()
+	}()
+
+	log.Printf("TCP: Session started for %s", c.RemoteAddr())
+	newSession(ctx, h, c).run()
+	log.Printf("TCP: Session ended for %s", c.RemoteAddr())
+}
+
+// newGame a game for a new session, from the level if there is one
+func (h *Host) newGame() (game.Game, error) {
+	if h.cfg.Level != "" {
+		return game.LoadLevel(h.cfg.Level)
+	}
+	grid := h.cfg.Grid
+	return game.AutoGame(grid, game.Point{X: grid.X / 2, Y: grid.Y * 3 / 4})
+}
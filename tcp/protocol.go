@@ -0,0 +1,133 @@
+package tcp
+
+import (
+	"fmt"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/server"
+	"strings"
+)
+
+/**
+ * The protocol lines.  Points are written as x,y and directions by name, as they
+ * are in level files.  The board rows are written from the largest Y down, as Up
+ * is +Y, with a character for every X:
+ *   .  empty
+ *   #  wall
+ *   F  food
+ *   S  the session snake head, and s the rest of it
+ *   X  any other snake head, and x the rest of it
+ */
+
+// the answer to HELP
+var helpLines = []string{
+	"TURN <up|down|left|right>  turn the snake",
+	"TICK                       move the game on one tick",
+	"STATE                      show the game",
+	"HELP                       show the commands",
+	"QUIT                       end the session",
+}
+
+// a point, as x,y
+func pointText(p game.Point) string {
+	return fmt.Sprintf("%d,%d", p.X, p.Y)
+}
+
+// an event, as its type and then whatever the type needs
+func eventLine(e server.Event) string {
+	s := e.Type.String()
+	switch e.Type {
+	case server.EventTicked:
+		s += fmt.Sprintf(" %d", e.Tick)
+	case server.EventMoved, server.EventGrew:
+		s += fmt.Sprintf(" %d %s", e.Snake, pointText(e.Point))
+	case server.EventDied, server.EventWon:
+		return s + fmt.Sprintf(" %d %s %s", e.Snake, pointText(e.Point), e.Reason)
+	case server.EventAte:
+		s += fmt.Sprintf(" %d %s %s", e.Snake, pointText(e.Food.Point), e.Food.Kind)
+	case server.EventFoodPlaced, server.EventFoodRejected:
+		s += fmt.Sprintf(" %s %s", pointText(e.Food.Point), e.Food.Kind)
+	case server.EventTurned, server.EventTurnRejected:
+		s += fmt.Sprintf(" %d %s", e.Snake, game.DirectionName(e.Dir))
+	case server.EventSpeed:
+		s += fmt.Sprintf(" %d", e.Speed)
+	case server.EventClock:
+		s += fmt.Sprintf(" %s", e.Interval)
+	}
+	if e.Err != nil {
+		s += " " + e.Err.Error()
+	}
+	return s
+}
+
+// the game state, for a snake
+func stateLines(g *game.Game, id game.SnakeID) []string {
+	ls := []string{
+		fmt.Sprintf("TICK %d", g.Ticks()),
+		fmt.Sprintf("SIZE %d %d", g.Size().X, g.Size().Y),
+	}
+	if sn, err := g.Snake(id); err == nil {
+		ps := []string{}
+		for _, p := range sn.Points() {
+			ps = append(ps, pointText(p))
+		}
+		ls = append(ls,
+			"HEAD "+pointText(sn.HeadPoint()),
+			"FACING "+game.DirectionName(sn.Facing()),
+			"SNAKE "+strings.Join(ps, " "),
+		)
+	}
+	for _, f := range g.Foods() {
+		ls = append(ls, fmt.Sprintf("FOOD %s %s", pointText(f.Point), f.Kind))
+	}
+	if sc, err := g.Score(id); err == nil {
+		ls = append(ls, fmt.Sprintf("SCORE %d", sc.Points))
+	}
+	if r, err := g.Result(id); err == nil {
+		ls = append(ls, fmt.Sprintf("STATUS %s", r.Reason))
+	}
+	for _, row := range board(g, id) {
+		ls = append(ls, "ROW "+row)
+	}
+	return ls
+}
+
+// the board, as rows from the largest Y down
+func board(g *game.Game, id game.SnakeID) []string {
+	size := g.Size()
+	rows := make([][]byte, size.Y+1)
+	for i := range rows {
+		rows[i] = []byte(strings.Repeat(".", size.X+1))
+	}
+	set := func(p game.Point, c byte) {
+		if p.X >= 0 && p.X <= size.X && p.Y >= 0 && p.Y <= size.Y {
+			rows[size.Y-p.Y][p.X] = c
+		}
+	}
+
+	for _, p := range g.Obstacles().Points() {
+		set(p, '#')
+	}
+	for _, f := range g.Foods() {
+		set(f.Point, 'F')
+	}
+	for _, sid := range g.Snakes() {
+		head, body := byte('X'), byte('x')
+		if sid == id {
+			head, body = 'S', 's'
+		}
+		sn, _ := g.Snake(sid)
+		for i, p := range sn.Points() {
+			if i == 0 {
+				set(p, head)
+			} else {
+				set(p, body)
+			}
+		}
+	}
+
+	rs := make([]string, len(rows))
+	for i, r := range rows {
+		rs[i] = string(r)
+	}
+	return rs
+}
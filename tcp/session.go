@@ -0,0 +1,204 @@
+package tcp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/server"
+	"net"
+	"strings"
+)
+
+/**
+ * A session is one connection, and the game that it plays.
+ *
+ * Commands are read one line at a time, and each is answered before the next is
+ * read: first with any data lines, and then with OK or ERR.  The session plays
+ * the first snake in the game.
+ */
+
+// the most events to buffer for a session, between its commands
+const sessionEventBuffer = 256
+
+// the snake that a session plays
+const sessionSnake = game.SnakeID(0)
+
+var (
+	errNoCommand      = errors.New("Could not run the command, it is unknown (try HELP)")
+	errNoDirection    = errors.New("Could not turn, TURN needs a direction (up, down, left or right)")
+	errTooManyArgs    = errors.New("Could not run the command, it has too many arguments")
+	errSessionStopped = errors.New("Could not run the command, the session has stopped")
+)
+
+// a connection, and its game
+type session struct {
+	ctx  context.Context
+	host *Host
+	conn net.Conn
+	in   *bufio.Scanner
+	out  *bufio.Writer
+
+	g   *game.Game
+	srv *server.Server
+	sub *server.Subscription
+
+	results []game.GameResult // the final results, once the game is over
+}
+
+// a new session on a connection
+func newSession(ctx context.Context, h *Host, c net.Conn) *session {
+	return &session{ctx: ctx, host: h, conn: c, in: bufio.NewScanner(c), out: bufio.NewWriter(c)}
+}
+
+// start the game, and answer commands until the client quits or goes away
+func (s *session) run() {
+	if err := s.start(); err != nil {
+		s.reply(err)
+		return
+	}
+	s.line("HELLO snake %d", sessionSnake)
+	s.line("SIZE %d %d", s.g.Size().X, s.g.Size().Y)
+	s.reply(nil)
+
+	for s.in.Scan() {
+		fs := strings.Fields(s.in.Text())
+		if len(fs) == 0 {
+			continue
+		}
+		quit, err := s.command(strings.ToUpper(fs[0]), fs[1:])
+		s.reply(err)
+		if quit {
+			return
+		}
+	}
+}
+
+// start a server for a new game
+func (s *session) start() error {
+	g, err := s.host.newGame()
+	if err != nil {
+		return err
+	}
+	srv := server.NewServer(&g)
+	srv.FoodCount = s.host.cfg.FoodCount
+	s.g, s.srv = &g, &srv
+	s.sub = srv.Subscribe(sessionEventBuffer)
+
+	srv.RandomFood()
+	go srv.Start(s.ctx)
+	return nil
+}
+
+// run a command, returning true if the session should end
+func (s *session) command(name string, args []string) (bool, error) {
+	switch name {
+	case "TURN":
+		if len(args) != 1 {
+			return false, errNoDirection
+		}
+		d, err := game.ParseDirection(strings.ToLower(args[0]))
+		if err != nil {
+			return false, fmt.Errorf("Could not turn, %s", err)
+		}
+		return false, s.send(func() error { return s.srv.Turn(s.ctx, d) })
+	case "TICK":
+		if len(args) != 0 {
+			return false, errTooManyArgs
+		}
+		return false, s.send(func() error { return s.srv.Tick(s.ctx) })
+	case "STATE":
+		if len(args) != 0 {
+			return false, errTooManyArgs
+		}
+		return false, s.state()
+	case "HELP":
+		for _, h := range helpLines {
+			s.line("HELP %s", h)
+		}
+		return false, nil
+	case "QUIT":
+		s.line("BYE")
+		return true, nil
+	default:
+		return false, errNoCommand
+	}
+}
+
+// send a command to the server, and write out the events that it caused
+func (s *session) send(cmd func() error) error {
+	if err := cmd(); err != nil {
+		return err
+	}
+	s.events()
+	return nil
+}
+
+// write out the events that the server has emitted.  A view runs once the server
+// is done with the last command, so by then all of its events are waiting.
+func (s *session) events() {
+	if err := s.srv.View(s.ctx, func(*game.Game) {}); err != nil {
+		// the server is stopping, and closes the feed once it has the results
+		for e := range s.sub.C {
+			s.event(e)
+		}
+		s.over()
+		return
+	}
+	for {
+		select {
+		case e, ok := <-s.sub.C:
+			if !ok {
+				return
+			}
+			s.event(e)
+		default:
+			return
+		}
+	}
+}
+
+// write out the results, once the server has stopped
+func (s *session) over() {
+	if s.results != nil {
+		return
+	}
+	s.results = []game.GameResult{}
+	for r := range s.srv.Finished {
+		s.results = append(s.results, r)
+		s.line("OVER snake %d score %d length %d ticks %d food %d reason %s", r.Snake, r.Score, r.Length, r.Ticks, r.FoodEaten, r.Reason)
+	}
+}
+
+// write out the game state
+func (s *session) state() error {
+	var ls []string
+	if _, err := s.srv.ViewFinal(s.ctx, func(g *game.Game) { ls = stateLines(g, sessionSnake) }); err != nil {
+		return errSessionStopped
+	}
+	for _, l := range ls {
+		s.line("%s", l)
+	}
+	return nil
+}
+
+// write out an event
+func (s *session) event(e server.Event) {
+	s.line("EVENT %s", eventLine(e))
+}
+
+// write a line to the client
+func (s *session) line(format string, args ...interface{}) {
+	fmt.Fprintf(s.out, format+"\n", args...)
+}
+
+// finish the answer to a command, with OK or ERR
+func (s *session) reply(err error) {
+	if err != nil {
+		s.line("ERR %s", err)
+	} else {
+		s.line("OK")
+	}
+	s.out.Flush()
+}
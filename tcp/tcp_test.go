@@ -0,0 +1,184 @@
+package tcp_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/tcp"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// a test client session
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	in   *bufio.Scanner
+}
+
+// serve a test host, and return a func to stop it which waits for Serve
+func testHost(t *testing.T, cfg tcp.Config) (*tcp.Host, string, func() error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Could not listen: %s", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h := tcp.NewHost(cfg)
+	served := make(chan error, 1)
+	go func() { served <- h.Serve(ctx, l) }()
+
+	var once sync.Once
+	var serr error
+	stop := func() error {
+		once.Do(func() {
+			cancel()
+			select {
+			case serr = <-served:
+			case <-time.After(5 * time.Second):
+				t.Errorf("Host did not stop")
+			}
+		})
+		return serr
+	}
+	t.Cleanup(func() { stop() })
+	return h, l.Addr().String(), stop
+}
+
+// connect a client, and read the greeting
+func connect(t *testing.T, addr string) (*client, []string) {
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Could not connect: %s", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	c.SetDeadline(time.Now().Add(10 * time.Second))
+	cl := &client{t: t, conn: c, in: bufio.NewScanner(c)}
+	return cl, cl.reply()
+}
+
+// read lines up to and including an OK or ERR
+func (cl *client) reply() []string {
+	ls := []string{}
+	for cl.in.Scan() {
+		l := cl.in.Text()
+		ls = append(ls, l)
+		if l == "OK" || strings.HasPrefix(l, "ERR ") {
+			return ls
+		}
+	}
+	cl.t.Fatalf("Session ended before a reply: %v", ls)
+	return nil
+}
+
+// send a command, and read its reply
+func (cl *client) ask(cmd string) []string {
+	if _, err := fmt.Fprintln(cl.conn, cmd); err != nil {
+		cl.t.Fatalf("Could not send %s: %s", cmd, err)
+	}
+	return cl.reply()
+}
+
+// has a reply got a line with a prefix
+func has(ls []string, prefix string) bool {
+	for _, l := range ls {
+		if strings.HasPrefix(l, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Test a game played through a session, until the snake hits the boundary
+func Test_SessionPlay(t *testing.T) {
+	_, addr, _ := testHost(t, tcp.Config{Grid: game.Vector{X: 9, Y: 9}})
+	cl, hello := connect(t, addr)
+
+	if !has(hello, "HELLO snake 0") || !has(hello, "SIZE 9 9") || hello[len(hello)-1] != "OK" {
+		t.Fatalf("Received the wrong greeting: %v", hello)
+	}
+
+	st := cl.ask("STATE")
+	for _, want := range []string{"TICK 0", "HEAD 4,4", "FACING up", "FOOD 4,6 normal", "SCORE 0", "STATUS playing", "ROW ....F.....", "ROW ....S....."} {
+		if !has(st, want) {
+			t.Errorf("State is missing %q: %v", want, st)
+		}
+	}
+
+	if ls := cl.ask("tick"); !has(ls, "EVENT ticked 1") || !has(ls, "EVENT moved 0 4,5") || ls[len(ls)-1] != "OK" {
+		t.Errorf("Received the wrong tick reply: %v", ls)
+	}
+	if ls := cl.ask("TICK"); !has(ls, "EVENT ate 0 4,6 normal") || !has(ls, "EVENT food-placed ") {
+		t.Errorf("Snake did not eat: %v", ls)
+	}
+	if ls := cl.ask("TURN left"); !has(ls, "EVENT turned 0 left") {
+		t.Errorf("Snake did not turn: %v", ls)
+	}
+
+	var over []string
+	for i := 0; i < 10 && over == nil; i++ {
+		if ls := cl.ask("TICK"); has(ls, "OVER ") {
+			over = ls
+		}
+	}
+	if !has(over, "OVER snake 0 score ") || !strings.HasSuffix(over[len(over)-2], "reason boundary collision") {
+		t.Errorf("Received the wrong game over: %v", over)
+	}
+	if ls := cl.ask("TICK"); ls[len(ls)-1] != "ERR Could not reach the server, the game is over" {
+		t.Errorf("Tick after the game was over was not rejected: %v", ls)
+	}
+	if st := cl.ask("STATE"); !has(st, "STATUS boundary collision") {
+		t.Errorf("State after the game was over is wrong: %v", st)
+	}
+}
+
+// Test that bad commands are rejected, and that QUIT ends the session
+func Test_SessionCommands(t *testing.T) {
+	h, addr, _ := testHost(t, tcp.Config{})
+	cl, hello := connect(t, addr)
+
+	if !has(hello, "SIZE 19 19") {
+		t.Errorf("Session did not use the default grid: %v", hello)
+	}
+	if h.Sessions() != 1 {
+		t.Errorf("Host has the wrong session count: %d", h.Sessions())
+	}
+	for _, cmd := range []string{"JUMP", "TURN", "TURN sideways", "TICK 3"} {
+		if ls := cl.ask(cmd); len(ls) != 1 || !strings.HasPrefix(ls[0], "ERR Could not") {
+			t.Errorf("Command %q was not rejected: %v", cmd, ls)
+		}
+	}
+	if ls := cl.ask("help"); !has(ls, "HELP TURN ") || !has(ls, "HELP QUIT ") {
+		t.Errorf("Received the wrong help: %v", ls)
+	}
+
+	if ls := cl.ask("QUIT"); len(ls) != 2 || ls[0] != "BYE" {
+		t.Errorf("Received the wrong quit reply: %v", ls)
+	}
+	if cl.in.Scan() {
+		t.Errorf("Session did not end after QUIT: %s", cl.in.Text())
+	}
+}
+
+// Test that stopping the host ends its sessions
+func Test_HostStop(t *testing.T) {
+	h, addr, stop := testHost(t, tcp.Config{})
+	a, _ := connect(t, addr)
+	b, _ := connect(t, addr)
+	a.ask("TICK")
+
+	if err := stop(); err != nil {
+		t.Errorf("Host stopped with an error: %s", err)
+	}
+	for _, cl := range []*client{a, b} {
+		if cl.in.Scan() {
+			t.Errorf("Session did not end when the host stopped: %s", cl.in.Text())
+		}
+	}
+	if h.Sessions() != 0 {
+		t.Errorf("Host still has sessions after it stopped: %d", h.Sessions())
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+/**
+ * The telnet ui: a local TCP server which hosts snake games for bots, or for
+ * anyone with telnet.
+ *
+ * Run it, and `telnet localhost 4040` (or whatever -addr says) to play.  The
+ * protocol is in the tcp package README.
+ */
+
+import (
+	"context"
+	"flag"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/tcp"
+	"log"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:4040", "the address to serve the games on")
+	width := flag.Int("width", 20, "the grid width")
+	height := flag.Int("height", 20, "the grid height")
+	food := flag.Int("food", 1, "how much food to keep on the board")
+	level := flag.String("level", "", "a level file to play, instead of an empty grid")
+	flag.Parse()
+
+	h := tcp.NewHost(tcp.Config{Grid: game.Vector{X: *width - 1, Y: *height - 1}, FoodCount: *food, Level: *level})
+	log.Printf("Serving snake games on %s", *addr)
+	log.Fatal(h.ListenAndServe(context.Background(), *addr))
+}
@@ -0,0 +1,135 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/websocket"
+	"github.com/james-nesbitt/snake/game"
+	"log"
+	"sync"
+	"time"
+)
+
+/**
+ * A conn is one client WebSocket.  Messages to the client are queued and written
+ * by their own goroutine, so that a slow client never holds up the game: a
+ * client that falls more than connSendBuffer messages behind is disconnected.
+ * Commands from the client are read on the goroutine that served the request.
+ */
+
+const (
+	connSendBuffer   = 256              // how many messages can wait for a client
+	connWriteTimeout = 5 * time.Second  // how long a client has to take a message
+	connPingPeriod   = 30 * time.Second // how often to check that a client is there
+)
+
+var (
+	errNotStarted     = errors.New("Could not run the command, the game hasn't started")
+	errUnknownCommand = errors.New("Could not run the command, it is unknown")
+)
+
+// a client WebSocket
+type conn struct {
+	ws    *websocket.Conn
+	snake game.SnakeID // the snake that the client plays
+
+	out     chan []byte   // messages waiting to be written
+	done    chan struct{} // closed when the conn is closed
+	closing sync.Once
+}
+
+// a new client conn, which starts writing right away
+func newConn(ws *websocket.Conn) *conn {
+	c := &conn{ws: ws, out: make(chan []byte, connSendBuffer), done: make(chan struct{})}
+	go c.write()
+	return c
+}
+
+// send a message to the client, without blocking.  A client that has fallen
+// too far behind is closed.
+func (c *conn) send(m Message) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		log.Printf("WEB: Could not encode a %s message: %s", m.Type, err)
+		return
+	}
+	select {
+	case <-c.done:
+	case c.out <- b:
+	default:
+		log.Printf("WEB: Client fell behind, closing it")
+		c.close()
+	}
+}
+
+// send an error to the client, and close it
+func (c *conn) fail(err error) {
+	c.send(Message{Type: ErrorMessage, Error: err.Error()})
+	c.close()
+}
+
+// close the conn, once its waiting messages are written
+func (c *conn) close() {
+	c.closing.Do(func() { close(c.done) })
+}
+
+// write the waiting messages to the client, until it is closed
+func (c *conn) write() {
+	ping := time.NewTicker(connPingPeriod)
+	defer ping.Stop()
+	defer c.ws.Close()
+
+	for {
+		select {
+		case b := <-c.out:
+			if err := c.writeMessage(websocket.TextMessage, b); err != nil {
+				c.close()
+				return
+			}
+		case <-ping.C:
+			if err := c.writeMessage(websocket.PingMessage, nil); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			for {
+				select {
+				case b := <-c.out:
+					if c.writeMessage(websocket.TextMessage, b) != nil {
+						return
+					}
+				default:
+					c.writeMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *conn) writeMessage(mt int, b []byte) error {
+	c.ws.SetWriteDeadline(time.Now().Add(connWriteTimeout))
+	return c.ws.WriteMessage(mt, b)
+}
+
+// read commands from the client and pass them to the game, until the client
+// goes away
+func (c *conn) run(wg *webGame) {
+	defer wg.leave(c)
+	defer c.close()
+
+	for {
+		_, b, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		cmd := Command{}
+		if err := json.Unmarshal(b, &cmd); err != nil {
+			c.send(Message{Type: ErrorMessage, Error: "Could not read the command: " + err.Error()})
+			continue
+		}
+		if err := wg.command(c, cmd); err != nil {
+			c.send(Message{Type: ErrorMessage, Error: err.Error()})
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/server"
+	"github.com/james-nesbitt/snake/store"
+	"log"
+	"sync"
+)
+
+/**
+ * A web game is a server game with the clients that are connected to it.
+ *
+ * Until every snake has a player the game waits, and clients only get the
+ * board.  Then the server is started on its own clock, and every server event
+ * goes out to every client, with a state frame after each tick.  Once the server
+ * stops, every client gets the final board and the results, and is disconnected.
+ */
+
+// the most events to buffer for a game, between the server and its clients
+const gameEventBuffer = 256
+
+// a game, and its clients
+type webGame struct {
+	id   string
+	host *Host
+	g    *game.Game
+	srv  *server.Server
+
+	ctx    context.Context
+	cancel func()
+
+	mu      sync.Mutex
+	players []string       // the player for each snake, "" until it has joined
+	joined  []bool         // which snakes have a player
+	conns   map[*conn]bool // the connected clients
+	started bool           // the server has been started
+}
+
+// a new game, waiting for its players
+func newWebGame(h *Host, id string, g *game.Game, players int) *webGame {
+	srv := server.NewServer(g)
+	srv.SelfClock = true
+	srv.ClockRules = h.cfg.Clock
+	srv.FoodCount = h.cfg.FoodCount
+	srv.Store = h.cfg.Store
+
+	ctx, cancel := context.WithCancel(h.ctx)
+	return &webGame{
+		id:      id,
+		host:    h,
+		g:       g,
+		srv:     &srv,
+		ctx:     ctx,
+		cancel:  cancel,
+		players: make([]string, players),
+		joined:  make([]bool, players),
+		conns:   map[*conn]bool{},
+	}
+}
+
+// join a client to the game, as the next snake without a player.  The game starts
+// once every snake has one.
+func (wg *webGame) join(c *conn, player string) error {
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+
+	id := -1
+	for i, j := range wg.joined {
+		if !j {
+			id = i
+			break
+		}
+	}
+	if id < 0 || wg.started {
+		return ErrGameFull
+	}
+	if player == "" {
+		player = fmt.Sprintf("player %d", id+1)
+	}
+	wg.joined[id], wg.players[id] = true, player
+	wg.conns[c] = true
+	c.snake = game.SnakeID(id)
+	log.Printf("WEB: %s joined game %s as snake %d", player, wg.id, id)
+
+	c.send(Message{Type: HelloMessage, Game: wg.id, Snake: c.snake})
+	if id < len(wg.joined)-1 {
+		// the game can't change before it starts, so it is safe to read
+		wg.broadcastLocked(Message{Type: StateMessage, State: wg.waitingState()})
+		return nil
+	}
+	wg.start()
+	return nil
+}
+
+// the board while the game is waiting for players
+func (wg *webGame) waitingState() *State {
+	st := newState(wg.g, wg.players)
+	st.Waiting = true
+	return st
+}
+
+// start the server, once every snake has a player
+func (wg *webGame) start() {
+	wg.started = true
+	wg.srv.StoreInfo = store.GameInfo{Level: wg.host.cfg.Level, Players: append([]string{}, wg.players...)}
+	sub := wg.srv.Subscribe(gameEventBuffer)
+	wg.broadcastLocked(Message{Type: StateMessage, State: newState(wg.g, wg.players)})
+
+	wg.srv.RandomFood()
+	go wg.srv.Start(wg.ctx)
+	go wg.run(sub)
+	log.Printf("WEB: Started game %s", wg.id)
+}
+
+// Send the server events on to the clients, until the server stops
+func (wg *webGame) run(sub *server.Subscription) {
+	var next *server.Event // an event that was read early, for the next tick
+	for {
+		var e server.Event
+		if next != nil {
+			e, next = *next, nil
+		} else {
+			var ok bool
+			if e, ok = <-sub.C; !ok {
+				break
+			}
+		}
+		wg.broadcast(Message{Type: EventMessage, Event: newEvent(e)})
+		if e.Type != server.EventTicked {
+			continue
+		}
+
+		// the view runs once the tick is done, so its events are all waiting
+		var st *State
+		if err := wg.srv.View(wg.ctx, func(g *game.Game) { st = newState(g, wg.players) }); err != nil {
+			continue
+		}
+	tick:
+		for {
+			select {
+			case te, ok := <-sub.C:
+				if !ok {
+					break tick
+				}
+				if te.Type == server.EventTicked {
+					next = &te
+					break tick
+				}
+				wg.broadcast(Message{Type: EventMessage, Event: newEvent(te)})
+			default:
+				break tick
+			}
+		}
+		wg.broadcast(Message{Type: StateMessage, State: st})
+	}
+	wg.finish()
+}
+
+// send the final board and results to the clients, and disconnect them
+func (wg *webGame) finish() {
+	rs := []game.GameResult{}
+	for r := range wg.srv.Finished {
+		rs = append(rs, r)
+	}
+	log.Printf("WEB: Game %s is over", wg.id)
+	wg.host.remove(wg.id)
+	wg.cancel()
+
+	var st *State
+	wg.srv.ViewFinal(context.Background(), func(g *game.Game) { st = newState(g, wg.players) })
+
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	wg.broadcastLocked(Message{Type: StateMessage, State: st})
+	wg.broadcastLocked(Message{Type: ResultsMessage, Results: rs})
+	for c := range wg.conns {
+		c.close()
+	}
+	wg.conns = map[*conn]bool{}
+}
+
+// take a client out of the game.  A game without any clients left is stopped.
+func (wg *webGame) leave(c *conn) {
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	if !wg.conns[c] {
+		return
+	}
+	delete(wg.conns, c)
+	if !wg.started {
+		// free the snake for someone else
+		wg.joined[c.snake], wg.players[c.snake] = false, ""
+	}
+	if len(wg.conns) > 0 {
+		return
+	}
+	log.Printf("WEB: Everyone left game %s", wg.id)
+	if !wg.started {
+		wg.host.remove(wg.id)
+	}
+	wg.cancel()
+}
+
+// handle a command from a client
+func (wg *webGame) command(c *conn, cmd Command) error {
+	wg.mu.Lock()
+	started := wg.started
+	wg.mu.Unlock()
+	if !started {
+		return errNotStarted
+	}
+
+	switch cmd.Type {
+	case "turn":
+		d, err := game.ParseDirection(cmd.Dir)
+		if err != nil {
+			return err
+		}
+		return wg.srv.TurnSnake(wg.ctx, c.snake, d)
+	case "pause":
+		return wg.srv.Pause(wg.ctx)
+	case "resume":
+		return wg.srv.Resume(wg.ctx)
+	default:
+		return errUnknownCommand
+	}
+}
+
+// send a message to every client
+func (wg *webGame) broadcast(m Message) {
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	wg.broadcastLocked(m)
+}
+
+func (wg *webGame) broadcastLocked(m Message) {
+	for c := range wg.conns {
+		c.send(m)
+	}
+}
@@ -0,0 +1,206 @@
+package web
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/server"
+	"github.com/james-nesbitt/snake/store"
+	"io/fs"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+/**
+ * A web Host serves snake games over WebSocket, along with a static HTML/JS
+ * canvas client, so that a browser can play against a local binary with no
+ * outside services.
+ *
+ * The Host is an http.Handler:
+ *   /      : the static client
+ *   /ws    : a game WebSocket
+ *
+ * Every WebSocket creates or joins a game, with query parameters:
+ *   game    : the id of a game to join, or empty to create a new one
+ *   players : how many snakes a new game has (1 if it isn't set)
+ *   player  : the player name, for high scores
+ *
+ * Each game is a server.Server on its own clock, which starts once every snake
+ * has a player.  The game runs until it is over, or until every client has
+ * left.
+ */
+
+//go:embed static
+var static embed.FS
+
+// Config for the games that a host creates
+type Config struct {
+	Grid       game.Vector       // the largest X and Y on the grid (29,29 if it isn't set, at most 199,199)
+	FoodCount  int               // how much food to keep on the board (at most 100)
+	Clock      server.ClockRules // how fast the games are (server.DefaultClockRules if they aren't set)
+	MaxPlayers int               // the most snakes a game can have (4 if it isn't set, at most 8)
+	Store      *store.Store      // (optional) keeps the final results, for high scores
+	Level      string            // the level name for the stored results
+}
+
+// the default grid for web games
+var defaultGrid = game.Vector{X: 29, Y: 29}
+
+const (
+	defaultMaxPlayers = 4   // the default most snakes in a web game
+	maxGrid           = 199 // the largest X and Y that a grid can have
+	maxPlayers        = 8   // the most snakes that a web game can have
+	maxFood           = 100 // the most food that a web game can keep on the board
+)
+
+// Host of web games
+type Host struct {
+	cfg      Config
+	ctx      context.Context
+	mux      *http.ServeMux
+	upgrader websocket.Upgrader
+
+	mu    sync.Mutex
+	games map[string]*webGame
+	next  int
+}
+
+// NewHost a host for web games, which stops all of its games when the context is
+// done
+func NewHost(ctx context.Context, cfg Config) *Host {
+	if cfg.Grid.X < 1 || cfg.Grid.Y < 1 {
+		cfg.Grid = defaultGrid
+	}
+	if cfg.Grid.X > maxGrid {
+		cfg.Grid.X = maxGrid
+	}
+	if cfg.Grid.Y > maxGrid {
+		cfg.Grid.Y = maxGrid
+	}
+	if cfg.MaxPlayers < 1 {
+		cfg.MaxPlayers = defaultMaxPlayers
+	}
+	if cfg.MaxPlayers > maxPlayers {
+		cfg.MaxPlayers = maxPlayers
+	}
+	if cfg.FoodCount > maxFood {
+		cfg.FoodCount = maxFood
+	}
+
+	h := &Host{cfg: cfg, ctx: ctx, mux: http.NewServeMux(), games: map[string]*webGame{}}
+	sfs, _ := fs.Sub(static, "static")
+	h.mux.Handle("/", http.FileServer(http.FS(sfs)))
+	h.mux.HandleFunc("/ws", h.serveWS)
+	return h
+}
+
+// ServeHTTP the client and the game WebSockets
+func (h *Host) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// Games the ids of the games being hosted, in order
+func (h *Host) Games() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ids := []string{}
+	for id := range h.games {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+var (
+	// ErrNoGame there is no game with the id that was asked for
+	ErrNoGame = errors.New("Could not join the game, there is no such game")
+	// ErrGameFull every snake in the game already has a player
+	ErrGameFull = errors.New("Could not join the game, it is full")
+)
+
+// Upgrade a request to a game WebSocket, and create or join a game
+func (h *Host) serveWS(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WEB: Could not upgrade a connection: %s", err)
+		return
+	}
+	c := newConn(ws)
+
+	var wg *webGame
+	if id := q.Get("game"); id != "" {
+		h.mu.Lock()
+		wg = h.games[id]
+		h.mu.Unlock()
+		if wg == nil {
+			c.fail(ErrNoGame)
+			return
+		}
+	} else {
+		n := 0
+		if p := q.Get("players"); p != "" {
+			if n, err = strconv.Atoi(p); err != nil || n < 1 {
+				c.fail(fmt.Errorf("Could not create a game, bad player count %q", p))
+				return
+			}
+		}
+		if wg, err = h.create(n); err != nil {
+			c.fail(err)
+			return
+		}
+	}
+
+	if err := wg.join(c, q.Get("player")); err != nil {
+		c.fail(err)
+		return
+	}
+	c.run(wg)
+}
+
+// create a new game for some players
+func (h *Host) create(players int) (*webGame, error) {
+	if players < 1 {
+		players = 1
+	}
+	if players > h.cfg.MaxPlayers {
+		return nil, fmt.Errorf("Could not create a game for %d players, the most is %d", players, h.cfg.MaxPlayers)
+	}
+	g, err := newGame(h.cfg.Grid, players)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.next++
+	id := strconv.Itoa(h.next)
+	wg := newWebGame(h, id, &g, players)
+	h.games[id] = wg
+	log.Printf("WEB: Created game %s for %d players", id, players)
+	return wg, nil
+}
+
+// forget a game that has ended
+func (h *Host) remove(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.games, id)
+}
+
+// newGame a game with snakes spread across the middle of the grid, all facing
+// up, and food above them
+func newGame(grid game.Vector, players int) (game.Game, error) {
+	ss := []game.Snake{}
+	for i := 0; i < players; i++ {
+		p := game.Point{X: (i + 1) * (grid.X + 1) / (players + 1), Y: grid.Y / 2}
+		ss = append(ss, game.NewSnake(p, game.Up))
+	}
+	return game.NewMultiGame(game.Grid(grid), ss, game.Point{X: grid.X / 2, Y: grid.Y * 3 / 4})
+}
@@ -0,0 +1,109 @@
+package web
+
+import (
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/server"
+)
+
+/**
+ * Everything sent over a game WebSocket is a JSON object with a type.
+ *
+ * Clients send commands:
+ *   {"type": "turn", "dir": "up"}   turn the client's snake (up, down, left, right)
+ *   {"type": "pause"}               pause the game
+ *   {"type": "resume"}              resume a paused game
+ *
+ * and receive messages:
+ *   hello   : the game id, and the snake that the client plays
+ *   state   : the whole board, after every tick (and while waiting for players)
+ *   event   : a server event, like a snake eating or dying
+ *   results : the final result for every snake, when the game is over
+ *   error   : a command (or the connection) was rejected
+ */
+
+// Command from a client
+type Command struct {
+	Type string `json:"type"`
+	Dir  string `json:"dir,omitempty"` // the direction, for turns
+}
+
+// Message types, sent to clients
+const (
+	HelloMessage   = "hello"
+	StateMessage   = "state"
+	EventMessage   = "event"
+	ResultsMessage = "results"
+	ErrorMessage   = "error"
+)
+
+// Message to a client
+type Message struct {
+	Type    string            `json:"type"`
+	Game    string            `json:"game,omitempty"`    // the game id, for hellos
+	Snake   game.SnakeID      `json:"snake"`             // the client's snake, for hellos
+	State   *State            `json:"state,omitempty"`   // the board, for states
+	Event   *Event            `json:"event,omitempty"`   // the event, for events
+	Results []game.GameResult `json:"results,omitempty"` // the final results, for results
+	Error   string            `json:"error,omitempty"`   // what went wrong, for errors
+}
+
+// Event a server event, with its error as text
+type Event struct {
+	server.Event
+	Error string `json:"error,omitempty"`
+}
+
+// newEvent the message form of a server event
+func newEvent(e server.Event) *Event {
+	we := &Event{Event: e}
+	if e.Err != nil {
+		we.Error = e.Err.Error()
+	}
+	return we
+}
+
+// State the whole board, as a client draws it
+type State struct {
+	Tick    uint         `json:"tick"`
+	Grid    game.Vector  `json:"grid"` // the largest X and Y on the grid (Up is +Y)
+	Walls   []game.Point `json:"walls,omitempty"`
+	Snakes  []Snake      `json:"snakes"`
+	Foods   []game.Food  `json:"foods"`
+	Speed   int          `json:"speed"`
+	Waiting bool         `json:"waiting"` // the game is waiting for players to join
+	Over    bool         `json:"over"`
+}
+
+// Snake a snake, as a client draws it
+type Snake struct {
+	ID     game.SnakeID `json:"id"`
+	Player string       `json:"player,omitempty"`
+	Points []game.Point `json:"points"` // head first
+	Facing string       `json:"facing"`
+	Alive  bool         `json:"alive"`
+	Score  int          `json:"score"`
+}
+
+// newState the state of a game, with the players for its snakes.  It must only be
+// called where the game can't change.
+func newState(g *game.Game, players []string) *State {
+	st := &State{
+		Tick:   g.Ticks(),
+		Grid:   g.Size(),
+		Walls:  g.Obstacles().Points(),
+		Snakes: []Snake{},
+		Foods:  g.Foods(),
+		Speed:  g.Speed(),
+		Over:   g.Over(),
+	}
+	for _, id := range g.Snakes() {
+		sn, _ := g.Snake(id)
+		sc, _ := g.Score(id)
+		ws := Snake{ID: id, Points: sn.Points(), Facing: game.DirectionName(sn.Facing()), Alive: g.Alive(id), Score: sc.Points}
+		if int(id) < len(players) {
+			ws.Player = players[id]
+		}
+		st.Snakes = append(st.Snakes, ws)
+	}
+	return st
+}
@@ -0,0 +1,136 @@
+/**
+ * The snake canvas client.  It connects a game WebSocket, draws every state
+ * frame that it is sent, and sends the arrow keys (or WASD) as turns.  Space
+ * pauses and resumes the game.
+ *
+ * The game grid has Up as +Y, so rows are drawn from the top of the grid down.
+ */
+(function () {
+  "use strict";
+
+  var colours = ["#4c4", "#48f", "#f84", "#c4c"];
+  var keys = {
+    ArrowUp: "up", ArrowDown: "down", ArrowLeft: "left", ArrowRight: "right",
+    w: "up", s: "down", a: "left", d: "right"
+  };
+
+  var canvas = document.getElementById("board");
+  var ctx = canvas.getContext("2d");
+  var status = document.getElementById("status");
+  var scores = document.getElementById("scores");
+  var log = document.getElementById("log");
+
+  var ws = null;
+  var gameId = "";
+  var snake = 0;
+  var paused = false;
+
+  function say(text) {
+    status.textContent = text;
+  }
+
+  function note(text) {
+    log.textContent = text + "\n" + log.textContent.slice(0, 4000);
+  }
+
+  function send(cmd) {
+    if (ws && ws.readyState === WebSocket.OPEN) {
+      ws.send(JSON.stringify(cmd));
+    }
+  }
+
+  function draw(st) {
+    var w = st.grid.X + 1, h = st.grid.Y + 1;
+    var cell = Math.floor(Math.min(canvas.width / w, canvas.height / h));
+    function fill(p, colour) {
+      ctx.fillStyle = colour;
+      ctx.fillRect(p.X * cell, (st.grid.Y - p.Y) * cell, cell - 1, cell - 1);
+    }
+
+    ctx.clearRect(0, 0, canvas.width, canvas.height);
+    ctx.strokeStyle = "#333";
+    ctx.strokeRect(0, 0, w * cell, h * cell);
+    (st.walls || []).forEach(function (p) { fill(p, "#777"); });
+    st.foods.forEach(function (f) { fill(f.point, f.kind === "normal" ? "#e33" : "#ee3"); });
+    st.snakes.forEach(function (s) {
+      var colour = s.alive ? colours[s.id % colours.length] : "#555";
+      s.points.forEach(function (p, i) { fill(p, i === 0 ? "#fff" : colour); });
+    });
+
+    scores.textContent = st.snakes.map(function (s) {
+      return (s.id === snake ? "* " : "  ") + (s.player || "snake " + s.id) + ": " + s.score + (s.alive ? "" : " (dead)");
+    }).join("   ");
+    if (st.waiting) {
+      say("Waiting for players to join game " + gameId + "...");
+    }
+  }
+
+  function connect(player, players, game) {
+    if (ws) {
+      ws.close();
+    }
+    var q = "?player=" + encodeURIComponent(player) + "&players=" + players;
+    if (game) {
+      q += "&game=" + encodeURIComponent(game);
+    }
+    var proto = location.protocol === "https:" ? "wss://" : "ws://";
+    ws = new WebSocket(proto + location.host + "/ws" + q);
+
+    ws.onmessage = function (msg) {
+      var m = JSON.parse(msg.data);
+      switch (m.type) {
+        case "hello":
+          gameId = m.game;
+          snake = m.snake;
+          say("Playing game " + m.game + " as snake " + m.snake);
+          break;
+        case "state":
+          draw(m.state);
+          break;
+        case "event":
+          var e = m.event;
+          if (e.type === "paused" || e.type === "resumed") {
+            paused = e.type === "paused";
+            say(paused ? "Paused (space to resume)" : "Playing game " + gameId);
+          }
+          if (e.type !== "ticked" && e.type !== "moved") {
+            note("#" + e.seq + " [tick " + e.tick + "] " + e.type + (e.error ? ": " + e.error : ""));
+          }
+          break;
+        case "results":
+          say("Game over: " + m.results.map(function (r) {
+            return "snake " + r.snake + " scored " + r.score;
+          }).join(", "));
+          break;
+        case "error":
+          note("error: " + m.error);
+          say(m.error);
+          break;
+      }
+    };
+    ws.onclose = function () {
+      note("disconnected");
+    };
+  }
+
+  document.getElementById("join").addEventListener("submit", function (ev) {
+    ev.preventDefault();
+    connect(document.getElementById("player").value,
+      document.getElementById("players").value,
+      document.getElementById("game").value);
+    canvas.focus();
+  });
+
+  document.addEventListener("keydown", function (ev) {
+    if (ev.target.tagName === "INPUT") {
+      return;
+    }
+    if (keys[ev.key]) {
+      ev.preventDefault();
+      send({ type: "turn", dir: keys[ev.key] });
+    } else if (ev.key === " ") {
+      ev.preventDefault();
+      send({ type: paused ? "resume" : "pause" });
+    }
+  });
+})();
@@ -0,0 +1,141 @@
+package web_test
+
+import (
+	"context"
+	"github.com/gorilla/websocket"
+	"github.com/james-nesbitt/snake/game"
+	"github.com/james-nesbitt/snake/server"
+	"github.com/james-nesbitt/snake/web"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// a test host, with a fast clock
+func testHost(t *testing.T, grid game.Vector, period time.Duration) (*web.Host, *httptest.Server) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	h := web.NewHost(ctx, web.Config{Grid: grid, Clock: server.ClockRules{Period: period}})
+	hs := httptest.NewServer(h)
+	t.Cleanup(hs.Close)
+	return h, hs
+}
+
+// connect a game WebSocket
+func dial(t *testing.T, hs *httptest.Server, query string) *websocket.Conn {
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(hs.URL, "http")+"/ws?"+query, nil)
+	if err != nil {
+		t.Fatalf("Could not connect a game WebSocket: %s", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	ws.SetReadDeadline(time.Now().Add(10 * time.Second))
+	return ws
+}
+
+// read the next message of a type, skipping any others
+func readMessage(t *testing.T, ws *websocket.Conn, mt string) web.Message {
+	for {
+		m := web.Message{}
+		if err := ws.ReadJSON(&m); err != nil {
+			t.Fatalf("Did not receive an expected %s message: %s", mt, err)
+		}
+		if m.Type == mt {
+			return m
+		}
+	}
+}
+
+// Test that the host serves the static client
+func Test_HostClient(t *testing.T) {
+	_, hs := testHost(t, game.Vector{X: 9, Y: 9}, time.Second)
+
+	for path, want := range map[string]string{"/": "<canvas", "/client.js": "WebSocket"} {
+		r, err := http.Get(hs.URL + path)
+		if err != nil {
+			t.Fatalf("Could not get %s: %s", path, err)
+		}
+		b, _ := io.ReadAll(r.Body)
+		r.Body.Close()
+		if r.StatusCode != http.StatusOK || !strings.Contains(string(b), want) {
+			t.Errorf("Host served the wrong %s: %d %s", path, r.StatusCode, b)
+		}
+	}
+}
+
+// Test playing a one player game, until the snake hits the boundary
+func Test_HostPlay(t *testing.T) {
+	h, hs := testHost(t, game.Vector{X: 9, Y: 9}, 20*time.Millisecond)
+	ws := dial(t, hs, "player=ann")
+
+	if m := readMessage(t, ws, web.HelloMessage); m.Game != "1" || m.Snake != 0 {
+		t.Errorf("Received the wrong hello: %+v", m)
+	}
+	if m := readMessage(t, ws, web.StateMessage); len(m.State.Snakes) != 1 || m.State.Snakes[0].Player != "ann" || m.State.Waiting {
+		t.Errorf("Received the wrong first state: %+v", m.State)
+	}
+	if err := ws.WriteJSON(web.Command{Type: "turn", Dir: "left"}); err != nil {
+		t.Fatalf("Could not send a turn: %s", err)
+	}
+	for m := readMessage(t, ws, web.EventMessage); m.Event.Type != server.EventTurned; m = readMessage(t, ws, web.EventMessage) {
+		if m.Event.Type == server.EventDied {
+			t.Fatalf("Snake died before the turn was applied: %+v", m.Event)
+		}
+	}
+	if m := readMessage(t, ws, web.StateMessage); m.State.Snakes[0].Facing != "left" {
+		t.Errorf("Turn was not applied: %+v", m.State.Snakes[0])
+	}
+
+	m := readMessage(t, ws, web.ResultsMessage)
+	if len(m.Results) != 1 || m.Results[0].Reason != game.HitBoundary {
+		t.Errorf("Received the wrong results: %+v", m.Results)
+	}
+	if _, _, err := ws.ReadMessage(); err == nil {
+		t.Errorf("Host did not disconnect after the game was over")
+	}
+	if gs := h.Games(); len(gs) != 0 {
+		t.Errorf("Host still has games after they are over: %v", gs)
+	}
+}
+
+// Test joining a two player game
+func Test_HostJoin(t *testing.T) {
+	_, hs := testHost(t, game.Vector{X: 9, Y: 9}, time.Second)
+	a := dial(t, hs, "player=ann&players=2")
+
+	readMessage(t, a, web.HelloMessage)
+	if m := readMessage(t, a, web.StateMessage); !m.State.Waiting || len(m.State.Snakes) != 2 {
+		t.Errorf("Game did not wait for the second player: %+v", m.State)
+	}
+	if err := a.WriteJSON(web.Command{Type: "turn", Dir: "left"}); err != nil {
+		t.Fatalf("Could not send a turn: %s", err)
+	}
+	if m := readMessage(t, a, web.ErrorMessage); !strings.Contains(m.Error, "hasn't started") {
+		t.Errorf("Turn before the game started was not rejected: %s", m.Error)
+	}
+
+	b := dial(t, hs, "player=bob&game=1")
+	if m := readMessage(t, b, web.HelloMessage); m.Game != "1" || m.Snake != 1 {
+		t.Errorf("Second player got the wrong hello: %+v", m)
+	}
+	for _, ws := range []*websocket.Conn{a, b} {
+		if m := readMessage(t, ws, web.StateMessage); m.State.Waiting || m.State.Snakes[1].Player != "bob" {
+			t.Errorf("Game did not start with both players: %+v", m.State)
+		}
+	}
+
+	for q, want := range map[string]string{"game=1": "full", "game=7": "no such game", "players=9": "the most is 4", "players=-1": "bad player count", "players=9999999999999999999": "bad player count"} {
+		if m := readMessage(t, dial(t, hs, q), web.ErrorMessage); !strings.Contains(m.Error, want) {
+			t.Errorf("Joining with %s was not rejected: %s", q, m.Error)
+		}
+	}
+
+	if err := b.WriteJSON(web.Command{Type: "jump"}); err != nil {
+		t.Fatalf("Could not send a command: %s", err)
+	}
+	if m := readMessage(t, b, web.ErrorMessage); !strings.Contains(m.Error, "unknown") {
+		t.Errorf("Unknown command was not rejected: %s", m.Error)
+	}
+}